@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	cfzap "cfzap/src"
+)
+
+// runConvert prints the config file resolved by cfzap in another format.
+func runConvert(args []string, stdout io.Writer, stderr io.Writer) int {
+	var flags configFlags
+	set := newConfigFlagSet(lookupCommand("convert"), stderr, &flags)
+	to := set.String("to", "yaml", "the output `format`, one of 'yaml', 'json' and 'toml'")
+
+	files, ok, code := parseArgs(set, args, 1, 1)
+	if !ok {
+		return code
+	}
+	file := files[0]
+
+	option, warnings, err := flags.configOption(file)
+	printWarnings(stderr, file, warnings)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", file, err)
+		return exitProblem
+	}
+
+	data, err := cfzap.ConvertConfig(option, *to)
+	if err != nil {
+		printProblems(stderr, file, describeProblems(err))
+		return exitProblem
+	}
+
+	_, _ = stdout.Write(data)
+
+	return exitOK
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	for _, format := range []string{"yaml", "json", "toml"} {
+		code, stdout, stderr := runCommand("convert", "--to", format, testFilePath+"cfzap.yaml")
+		assert.Equal(t, exitOK, code, stderr)
+
+		config := viper.New()
+		config.SetConfigType(format)
+		assert.Nil(t, config.ReadConfig(bytes.NewBufferString(stdout)), "the output should be valid "+format)
+		assert.Equal(t, []interface{}{"appender-file", "appender-stdout"}, config.Get("appenders"))
+		assert.Equal(t, "lumberjack2", config.GetString("appender-file.target"))
+		assert.Equal(t, 20, config.GetInt("lumberjack2.maxBackups"))
+	}
+
+	// yaml is the default format, and the XML config is converted.
+	code, stdout, stderr := runCommand("convert", testFilePath+"logback.xml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "appenders:\n- FILE\n- STDERR\n")
+	assert.Contains(t, stderr, "logback.xml: warning: ")
+
+	code, _, stderr = runCommand("convert", "--to", "xml", testFilePath+"cfzap.yaml")
+	assert.Equal(t, exitProblem, code)
+	assert.Contains(t, stderr, "unsupported format [xml]")
+
+	code, _, stderr = runCommand("convert", testFilePath+"logger_config.yaml")
+	assert.Equal(t, exitProblem, code)
+	assert.Contains(t, stderr, "missing section [appender-missing]")
+}
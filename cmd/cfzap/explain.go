@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	cfzap "cfzap/src"
+	"go.uber.org/zap/zapcore"
+)
+
+// runExplain prints the loggers of the config file as a tree:
+// loggers -> appenders -> targets, encoders and levels.
+func runExplain(args []string, stdout io.Writer, stderr io.Writer) int {
+	var flags configFlags
+	files, ok, code := parseArgs(newConfigFlagSet(lookupCommand("explain"), stderr, &flags), args, 1, 1)
+	if !ok {
+		return code
+	}
+	file := files[0]
+
+	option, warnings, err := flags.configOption(file)
+	printWarnings(stderr, file, warnings)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", file, err)
+		return exitProblem
+	}
+
+	// the loggers resolved are explained even if some appenders failed.
+	descriptions, err := cfzap.DescribeLoggers(option)
+	if len(descriptions) > 0 {
+		root := &treeNode{text: file}
+		if sources := descriptions[0].Sources; len(sources) > 0 {
+			root.add(explainSources(sources))
+		}
+		for _, description := range descriptions {
+			root.add(explainLogger(description))
+		}
+		root.print(stdout)
+	}
+
+	if err != nil {
+		printProblems(stderr, file, describeProblems(err))
+		return exitProblem
+	}
+
+	return exitOK
+}
+
+// treeNode is a node of the tree printed by explain.
+type treeNode struct {
+	text     string
+	children []*treeNode
+}
+
+// add adds the child node, and returns it.
+func (n *treeNode) add(child *treeNode) *treeNode {
+	n.children = append(n.children, child)
+	return child
+}
+
+// addText adds a child node with the text formatted, and returns it.
+func (n *treeNode) addText(format string, args ...interface{}) *treeNode {
+	return n.add(&treeNode{text: fmt.Sprintf(format, args...)})
+}
+
+// print prints the node and its children with the box drawing lines.
+func (n *treeNode) print(w io.Writer) {
+	fmt.Fprintln(w, n.text)
+	n.printChildren(w, "")
+}
+
+// printChildren prints the children of the node, prefix is the lines of the ancestors.
+func (n *treeNode) printChildren(w io.Writer, prefix string) {
+	for i, child := range n.children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		fmt.Fprintln(w, prefix+branch+child.text)
+		child.printChildren(w, prefix+indent)
+	}
+}
+
+// explainSources returns the node of the config files loaded.
+func explainSources(sources []cfzap.ConfigSource) *treeNode {
+	node := &treeNode{text: "sources"}
+	for _, source := range sources {
+		path := source.Path
+		if path == "" {
+			path = "(data)"
+		}
+		hash := source.Hash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		node.addText("%s (%s, sha256 %s)", path, source.Format, hash)
+	}
+
+	return node
+}
+
+// explainLogger returns the node of the logger.
+func explainLogger(description *cfzap.LoggerDescription) *treeNode {
+	node := &treeNode{text: "logger (top level)"}
+	if description.Name != "" {
+		node.text = fmt.Sprintf("logger [%s]", description.Name)
+	}
+
+	node.addText("options: %s", explainOptions(description.Options))
+
+	if len(description.Levels) > 0 {
+		levels := node.addText("levels")
+		names := make([]string, 0, len(description.Levels))
+		for name := range description.Levels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			levels.addText("%s: %s", name, description.Levels[name])
+		}
+	}
+
+	for _, appender := range description.Appenders {
+		node.add(explainAppender(appender))
+	}
+
+	return node
+}
+
+// explainOptions returns the text of the options.
+func explainOptions(options cfzap.OptionsDescription) string {
+	text := fmt.Sprintf("caller=%t development=%t", options.Caller, options.Development)
+	if len(options.Fields) > 0 {
+		text += " fields={" + joinPairs(options.Fields) + "}"
+	}
+
+	return text
+}
+
+// explainAppender returns the node of the appender.
+func explainAppender(appender cfzap.AppenderDescription) *treeNode {
+	node := &treeNode{text: fmt.Sprintf("appender [%s]", appender.Name)}
+	node.addText("level: %s", appender.Level)
+	if appender.Threshold != zapcore.DebugLevel {
+		node.addText("threshold: %s", appender.Threshold)
+	}
+
+	if appender.TargetKind == cfzap.TargetFile {
+		file := appender.File
+		target := node.addText("target: file [%s]", appender.Target)
+		target.addText("filename: %s", file.Filename)
+		target.addText("rolling: maxSize=%dMB maxAge=%dd maxBackups=%d localTime=%t compress=%t",
+			file.MaxSize, file.MaxAge, file.MaxBackups, file.LocalTime, file.Compress)
+	} else {
+		node.addText("target: %s", appender.TargetKind)
+	}
+
+	encoder := node.addText("encoder: %s [%s]", appender.EncoderType, appender.EncoderConfig)
+	e := appender.Encoder
+	if keys := joinSetPairs([][2]string{
+		{"message", e.MessageKey}, {"level", e.LevelKey}, {"time", e.TimeKey}, {"name", e.NameKey},
+		{"caller", e.CallerKey}, {"function", e.FunctionKey}, {"stacktrace", e.StacktraceKey},
+	}); keys != "" {
+		encoder.addText("keys: %s", keys)
+	}
+	if encoders := joinSetPairs([][2]string{
+		{"level", e.EncodeLevel}, {"time", e.EncodeTime}, {"duration", e.EncodeDuration}, {"caller", e.EncodeCaller},
+		{"name", e.EncodeName},
+	}); encoders != "" {
+		encoder.addText("encoders: %s", encoders)
+	}
+	if e.ConsoleSeparator != "" {
+		encoder.addText("separator: %q", e.ConsoleSeparator)
+	}
+	if e.LineEnding != "" {
+		encoder.addText("line ending: %q", e.LineEnding)
+	}
+
+	return node
+}
+
+// joinPairs returns 'key=value' of the map sorted by key, separated by space.
+func joinPairs(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + m[k]
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// joinSetPairs returns 'key=value' of the pairs whose values are not empty, separated by space.
+func joinSetPairs(pairs [][2]string) string {
+	var set []string
+	for _, pair := range pairs {
+		if pair[1] != "" {
+			set = append(set, pair[0]+"="+pair[1])
+		}
+	}
+
+	return strings.Join(set, " ")
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	code, stdout, stderr := runCommand("explain", testFilePath+"appender_config_ok.yaml")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, testFilePath+"appender_config_ok.yaml\n├── sources\n")
+	assert.Contains(t, stdout, "└── logger (top level)\n")
+	assert.Contains(t, stdout, "appender [")
+	assert.Contains(t, stdout, "── target: ")
+	assert.Contains(t, stdout, "── encoder: ")
+
+	// the loggers resolved are explained, and the failures are printed.
+	code, stdout, stderr = runCommand("explain", testFilePath+"logger_config.yaml")
+	assert.Equal(t, exitProblem, code)
+	assert.Contains(t, stdout, "├── logger (top level)\n")
+	assert.Contains(t, stdout, "├── logger [app]\n│   ├── options: caller=true development=false\n")
+	assert.Contains(t, stdout, "└── logger [audit]\n    ├── options: caller=false development=false\n"+
+		"    └── appender [appender-stderr]\n        ├── level: warn\n        ├── target: stderr\n"+
+		"        └── encoder: json [encoderConfig]\n            └── keys: message=MSG\n")
+	assert.NotContains(t, stdout, "logger [broken]")
+	assert.Equal(t, testFilePath+"logger_config.yaml: fail to load appender [appender-missing]: "+
+		"missing section [appender-missing]\n"+testFilePath+"logger_config.yaml: 1 problem(s) found\n", stderr)
+}
+
+func TestExplainMalformedAppenders(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "bad.yaml")
+	assert.Nil(t, os.WriteFile(file, []byte("appenders: [{name: appender-stdout}]\n"), 0644))
+
+	// the problem is reported as validate does, instead of panicking.
+	_, expected, _ := runCommand("validate", file)
+	for _, command := range []string{"explain", "convert"} {
+		code, stdout, stderr := runCommand(command, file)
+		assert.Equal(t, exitProblem, code, stderr)
+		assert.Equal(t, "", stdout, "nothing should be printed by "+command)
+		assert.Equal(t, expected, stderr, "the problems should be reported as validate by "+command)
+		assert.Contains(t, stderr, "[appenders[0]] is invalid: it should be an appender name")
+	}
+
+	// a number is taken as the name of a missing appender.
+	assert.Nil(t, os.WriteFile(file, []byte("appenders: [1]\n"), 0644))
+	code, _, stderr := runCommand("explain", file)
+	assert.Equal(t, exitProblem, code, stderr)
+	assert.Contains(t, stderr, "missing section [1]")
+}
+
+func TestTreeNode(t *testing.T) {
+	root := &treeNode{text: "root"}
+	a := root.addText("a %d", 1)
+	a.addText("a1")
+	a.addText("a2").addText("a21")
+	root.addText("b").addText("b1")
+
+	var stdout strings.Builder
+	root.print(&stdout)
+	assert.Equal(t, "root\n├── a 1\n│   ├── a1\n│   └── a2\n│       └── a21\n└── b\n    └── b1\n", stdout.String())
+}
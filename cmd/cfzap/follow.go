@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// follower reads the lines of a log file, and follows the file when it grows or is rotated by lumberjack,
+// which renames the file to a backup and creates a new one with the same name.
+// it reads a stream such as stdin when there's no path.
+type follower struct {
+	// the path of the log file, it's empty for a stream.
+	path string
+	// the interval to check the file at its end.
+	interval time.Duration
+	file     *os.File
+	info     os.FileInfo
+	reader   *bufio.Reader
+	// the partial line read at the end of the file.
+	pending []byte
+	// true if the file has been rotated, and the rest of it is being read before the new file is opened.
+	draining bool
+}
+
+// openFollower opens the log file to follow.
+func openFollower(path string, interval time.Duration) (*follower, error) {
+	f := &follower{path: path, interval: interval}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// newStreamFollower returns the follower reading the stream, it's never rotated.
+func newStreamFollower(r io.Reader) *follower {
+	return &follower{reader: bufio.NewReader(r)}
+}
+
+// open opens the file at path, and reads it from the beginning.
+func (f *follower) open() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file, f.info, f.reader = file, info, bufio.NewReader(file)
+
+	return nil
+}
+
+// close closes the file.
+func (f *follower) close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	return f.file.Close()
+}
+
+// next returns the next line without the line ending.
+// at the end of the file, it returns io.EOF unless wait is true, then it waits for more lines until ctx is done,
+// and opens the new file after the rest of the old one is read when the file is rotated.
+// the file is read again from the beginning when it's truncated.
+func (f *follower) next(ctx context.Context, wait bool) ([]byte, error) {
+	for {
+		chunk, err := f.reader.ReadBytes('\n')
+		f.pending = append(f.pending, chunk...)
+		if err == nil {
+			return f.takePending(), nil
+		}
+		if err != io.EOF {
+			return nil, err
+		}
+
+		if f.path == "" {
+			// the last line of a stream may have no line ending.
+			if len(f.pending) > 0 {
+				return f.takePending(), nil
+			}
+			return nil, io.EOF
+		}
+		if !wait {
+			return nil, io.EOF
+		}
+
+		if f.draining {
+			// the rest of the old file is read, the partial line is complete as no one writes to it any more.
+			_ = f.file.Close()
+			if err := f.open(); err != nil {
+				return nil, err
+			}
+			f.draining = false
+			if len(f.pending) > 0 {
+				return f.takePending(), nil
+			}
+			continue
+		}
+
+		changed, err := f.checkFile()
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(f.interval):
+		}
+	}
+}
+
+// checkFile checks to see if the file at path is rotated or truncated, it returns true if so.
+// the file may not exist for a moment during rotating, it's not changed then.
+func (f *follower) checkFile() (bool, error) {
+	info, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !os.SameFile(f.info, info) {
+		// read the lines written to the old file before it was renamed.
+		f.draining = true
+		return true, nil
+	}
+
+	offset, err := f.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		f.reader.Reset(f.file)
+		f.pending = nil
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// takePending returns the pending line without the line ending, and clears it.
+func (f *follower) takePending() []byte {
+	line := bytes.TrimSuffix(bytes.TrimSuffix(f.pending, []byte("\n")), []byte("\r"))
+	f.pending = nil
+
+	return line
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// appendFile appends the content to the file.
+func appendFile(t *testing.T, file string, content string) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	assert.Nil(t, err, "fail to open file.")
+	_, err = f.WriteString(content)
+	assert.Nil(t, err, "fail to write file.")
+	assert.Nil(t, f.Close())
+}
+
+// nextLine returns the next line read by the follower, waiting at most a second.
+func nextLine(t *testing.T, f *follower) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	line, err := f.next(ctx, true)
+	assert.Nil(t, err, "fail to read the next line.")
+
+	return string(line)
+}
+
+func TestFollower(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfzap-follow-test")
+	assert.Nil(t, err, "fail to create temp dir.")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "app.log")
+	appendFile(t, file, "1\r\n2\n3")
+
+	f, err := openFollower(file, 10*time.Millisecond)
+	assert.Nil(t, err, "fail to open the file.")
+	defer func() { _ = f.close() }()
+
+	// the partial line is not returned until it's completed.
+	line, err := f.next(context.Background(), false)
+	assert.Equal(t, "1", string(line))
+	line, _ = f.next(context.Background(), false)
+	assert.Equal(t, "2", string(line))
+	_, err = f.next(context.Background(), false)
+	assert.Equal(t, io.EOF, err)
+
+	appendFile(t, file, "3\n")
+	assert.Equal(t, "33", nextLine(t, f))
+
+	// rotated by lumberjack: the file is renamed, then a new one is created.
+	appendFile(t, file, "4\n")
+	assert.Equal(t, "4", nextLine(t, f))
+	appendFile(t, file, "5\n")
+	assert.Nil(t, os.Rename(file, filepath.Join(dir, "app-2021-06-01T00-00-00.000.log")))
+	appendFile(t, file, "6\n")
+	assert.Equal(t, "5", nextLine(t, f), "the rest of the old file should be read.")
+	assert.Equal(t, "6", nextLine(t, f), "the new file should be read.")
+
+	// truncated.
+	appendFile(t, file, "7\n")
+	assert.Equal(t, "7", nextLine(t, f))
+	assert.Nil(t, ioutil.WriteFile(file, []byte("8\n"), 0644))
+	assert.Equal(t, "8", nextLine(t, f))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = f.next(ctx, true)
+	assert.Equal(t, context.DeadlineExceeded, err, "it should wait for more lines until ctx is done.")
+}
+
+func TestStreamFollower(t *testing.T) {
+	f := newStreamFollower(strings.NewReader("1\n2"))
+
+	line, err := f.next(context.Background(), true)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(line))
+	line, err = f.next(context.Background(), true)
+	assert.Nil(t, err)
+	assert.Equal(t, "2", string(line), "the last line of the stream has no line ending.")
+	_, err = f.next(context.Background(), true)
+	assert.Equal(t, io.EOF, err)
+	assert.Nil(t, f.close())
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cfzap "cfzap/src"
+)
+
+// runInit writes a commented starter config generated from template, to the file or stdout.
+func runInit(args []string, stdout io.Writer, stderr io.Writer) int {
+	set := newFlagSet(lookupCommand("init"), stderr)
+
+	var names []string
+	for _, t := range cfzap.ConfigTemplates() {
+		names = append(names, t.Name)
+	}
+	template := set.String("template", cfzap.TemplateDevelopment,
+		"the `name` of the template, one of '"+strings.Join(names, "', '")+"'")
+	format := set.String("format", "", "the `format` of the config, one of ['"+
+		strings.Join(cfzap.GenerateFormats(), "', '")+"'], "+
+		"it's decided by the file extension by default, or 'yaml' for stdout")
+	force := set.Bool("force", false, "overwrite the file if it exists")
+	list := set.Bool("list", false, "list the templates")
+
+	files, ok, code := parseArgs(set, args, 0, 1)
+	if !ok {
+		return code
+	}
+
+	if *list {
+		for _, t := range cfzap.ConfigTemplates() {
+			fmt.Fprintf(stdout, "%-10s %s\n", t.Name, t.Description)
+		}
+		return exitOK
+	}
+
+	if *format == "" {
+		*format = "yaml"
+		if len(files) > 0 && filepath.Ext(files[0]) != "" {
+			*format = strings.TrimPrefix(filepath.Ext(files[0]), ".")
+		}
+	}
+
+	data, err := cfzap.GenerateConfig(*template, *format)
+	if err != nil {
+		fmt.Fprintf(stderr, "cfzap init: %v\n", err)
+		return exitUsage
+	}
+
+	if len(files) == 0 {
+		_, _ = stdout.Write(data)
+		return exitOK
+	}
+
+	file := files[0]
+	if _, err := os.Stat(file); err == nil && !*force {
+		fmt.Fprintf(stderr, "%s: the file exists, use --force to overwrite it\n", file)
+		return exitProblem
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", file, err)
+		return exitProblem
+	}
+	fmt.Fprintf(stdout, "%s: written from template [%s]\n", file, *template)
+
+	return exitOK
+}
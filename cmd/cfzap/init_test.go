@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInit(t *testing.T) {
+	code, stdout, _ := runCommand("init", "--list")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "dev ")
+	assert.Contains(t, stdout, "prod ")
+	assert.Contains(t, stdout, "container ")
+
+	// the development template in yaml is the default.
+	code, stdout, _ = runCommand("init")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "---\n# starter config of cfzap for local development")
+	assert.Contains(t, stdout, "encodeLevel: color\n")
+
+	code, stdout, _ = runCommand("init", "--template", "container", "--format", "json")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"logLevel": "${LOG_LEVEL:-info}"`)
+
+	code, _, stderr := runCommand("init", "--template", "staging")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown template [staging]")
+}
+
+func TestInitFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfzap-init-test")
+	assert.Nil(t, err, "fail to create temp dir.")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// the format is decided by the file extension.
+	file := filepath.Join(dir, "cfzap.toml")
+	code, stdout, stderr := runCommand("init", "--template", "prod", file)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, file+": written from template [prod]\n", stdout)
+
+	data, err := ioutil.ReadFile(file)
+	assert.Nil(t, err, "the file should be written.")
+	assert.Contains(t, string(data), "[lumberjack-file]\n")
+
+	// the file written is valid.
+	code, stdout, _ = runCommand("validate", file)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, file+": ok\n", stdout)
+
+	code, _, stderr = runCommand("init", file)
+	assert.Equal(t, exitProblem, code)
+	assert.Contains(t, stderr, "the file exists")
+
+	code, _, _ = runCommand("init", "--force", "--template", "container", file)
+	assert.Equal(t, exitOK, code)
+	data, _ = ioutil.ReadFile(file)
+	assert.NotContains(t, string(data), "[lumberjack-file]\n")
+
+	code, _, stderr = runCommand("init", filepath.Join(dir, "cfzap.ini"))
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "format [ini] cannot hold the config of cfzap")
+
+	// the help lists the formats supported.
+	_, _, stderr = runCommand("init", "--help")
+	assert.Contains(t, stderr, "one of ['json', 'toml', 'yaml', 'yml']")
+}
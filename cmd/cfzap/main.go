@@ -0,0 +1,214 @@
+// Command cfzap checks and converts the config files of cfzap without writing a line of Go.
+//
+// Usage:
+//
+//	cfzap validate [flags] <file>...            check the files and print all problems found
+//	cfzap explain [flags] <file>                print the loggers, appenders, targets, encoders and levels
+//	cfzap convert [flags] --to <format> <file>  print the resolved config in yaml, json or toml
+//	cfzap init [--template <name>] [file]       write a commented starter config
+//	cfzap pretty [--config <file>] [-f] [file]  render the JSON log lines as colored console lines
+//
+// The file can be any config file of cfzap, a zap.Config document, or log4j2.xml and logback.xml,
+// which are converted by package xmlimport first. The flags shared by the commands reading a file are:
+//
+//	--profile <name>       merge the profile config file, such as 'cfzap.prod.yaml' for 'cfzap.yaml'
+//	--overlay <file>       merge the overlay config file, it can be repeated
+//	--env-prefix <prefix>  override the values by the environment variables with the prefix
+//
+// The exit code is 0 when success, 1 when any problem is found in the config, and 2 for invalid arguments.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cfzap "cfzap/src"
+	"cfzap/src/xmlimport"
+)
+
+// the exit codes.
+const (
+	exitOK      = 0
+	exitProblem = 1
+	exitUsage   = 2
+)
+
+// command is a sub command of cfzap.
+type command struct {
+	name string
+	// the arguments shown in usage.
+	args string
+	// the description shown in usage.
+	summary string
+	// run runs the command with the arguments after the command name, and returns the exit code.
+	run func(args []string, stdout io.Writer, stderr io.Writer) int
+}
+
+// commands lists the sub commands in the order of usage.
+var commands []command
+
+func init() {
+	// the commands refer to usage(), so they are set up here to avoid the initialization cycle.
+	commands = []command{
+		{name: "validate", args: "[flags] <file>...", summary: "check the config files and print all problems found",
+			run: runValidate},
+		{name: "explain", args: "[flags] <file>",
+			summary: "print the loggers, appenders, targets, encoders and levels of the config file", run: runExplain},
+		{name: "convert", args: "[flags] --to yaml|json|toml <file>",
+			summary: "print the resolved config file in another format", run: runConvert},
+		{name: "init", args: "[flags] [file]", summary: "write a commented starter config file, to stdout if no file is given",
+			run: runInit},
+		{name: "pretty", args: "[flags] [file]",
+			summary: "render the JSON log file, or stdin if no file is given, as colored console lines", run: runPretty},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command named by the first argument, and returns the exit code.
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "cfzap: unknown command [%s]\n\n", args[0])
+	usage(stderr)
+
+	return exitUsage
+}
+
+// usage prints the usage of all commands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: cfzap <command> [flags] <file>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'cfzap <command> -h' for the flags of a command.")
+}
+
+// stringList is a flag which can be repeated, such as '--overlay a.yaml --overlay b.yaml'.
+type stringList []string
+
+// String implements flag.Value interface.
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value interface.
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// configFlags are the flags shared by all commands to read the config file.
+type configFlags struct {
+	profile   string
+	overlays  stringList
+	envPrefix string
+}
+
+// newFlagSet returns the flag set of the command, its usage is printed to stderr.
+func newFlagSet(c *command, stderr io.Writer) *flag.FlagSet {
+	set := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	set.SetOutput(stderr)
+	set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: cfzap %s %s\n\n%s.\n\nFlags:\n", c.name, c.args, c.summary)
+		set.PrintDefaults()
+	}
+
+	return set
+}
+
+// newConfigFlagSet returns the flag set of the command, with the shared flags defined in it.
+func newConfigFlagSet(c *command, stderr io.Writer, flags *configFlags) *flag.FlagSet {
+	set := newFlagSet(c, stderr)
+	set.StringVar(&flags.profile, "profile", "", "merge the profile config file, such as 'cfzap.prod.yaml' for 'cfzap.yaml'")
+	set.Var(&flags.overlays, "overlay", "merge the overlay config `file`, it can be repeated")
+	set.StringVar(&flags.envPrefix, "env-prefix", "", "override the values by the environment variables with the `prefix`")
+
+	return set
+}
+
+// lookupCommand returns the command by name.
+func lookupCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+
+	return nil
+}
+
+// parseArgs parses the flags of the command, and checks the number of files left, maxFiles is 0 for no limit.
+// it returns the files and true, or false with the exit code when the arguments are invalid or help is requested.
+func parseArgs(set *flag.FlagSet, args []string, minFiles int, maxFiles int) ([]string, bool, int) {
+	if err := set.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, false, exitOK
+		}
+		return nil, false, exitUsage
+	}
+
+	files := set.Args()
+	if len(files) < minFiles || (maxFiles > 0 && len(files) > maxFiles) {
+		set.Usage()
+		return nil, false, exitUsage
+	}
+
+	return files, true, exitOK
+}
+
+// configOption returns the ConfigOption reading the file with the flags.
+// log4j2.xml and logback.xml are converted to cfzap config first, and the warnings of converting are returned.
+func (f *configFlags) configOption(file string) (*cfzap.ConfigOption, []string, error) {
+	setters := []cfzap.ConfigPropertySetter{cfzap.WithOverlays(f.overlays...), cfzap.WithEnvPrefix(f.envPrefix)}
+	// the profile is taken from the environment variable 'CFZAP_PROFILE' by default.
+	if f.profile != "" {
+		setters = append(setters, cfzap.WithProfile(f.profile))
+	}
+
+	if !strings.EqualFold(filepath.Ext(file), ".xml") {
+		return cfzap.NewConfigOption(append(setters, cfzap.WithFilePath(file))...), nil, nil
+	}
+
+	result, err := xmlimport.ConvertFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.profile != "" || len(f.overlays) > 0 {
+		return nil, result.Warnings, fmt.Errorf("the profile and overlays are not supported for XML config [%s]", file)
+	}
+
+	return cfzap.NewConfigOption(append(setters, cfzap.WithData(result.Config, "yaml"))...), result.Warnings, nil
+}
+
+// printWarnings prints the warnings of converting XML config.
+func printWarnings(w io.Writer, file string, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "%s: warning: %s\n", file, warning)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFilePath = "../../src/test_config_file/"
+
+// runCommand runs cfzap with the arguments, and returns the exit code and the outputs.
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	code, _, stderr := runCommand()
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "Usage: cfzap <command>")
+
+	code, stdout, _ := runCommand("help")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "validate")
+	assert.Contains(t, stdout, "explain")
+	assert.Contains(t, stdout, "convert")
+
+	code, _, stderr = runCommand("bogus")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown command [bogus]")
+
+	// the help of a command is not an error.
+	code, _, stderr = runCommand("explain", "-h")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "Usage: cfzap explain")
+
+	code, _, _ = runCommand("explain", "--unknown", testFilePath+"logger_config.yaml")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, stderr = runCommand("explain", testFilePath+"logger_config.yaml", testFilePath+"cfzap.yaml")
+	assert.Equal(t, exitUsage, code, "explain accepts only one file.")
+	assert.Contains(t, stderr, "Usage: cfzap explain")
+}
+
+func TestConfigOption(t *testing.T) {
+	flags := &configFlags{envPrefix: "CFZAP", overlays: stringList{"a.yaml", "b.yaml"}}
+	option, warnings, err := flags.configOption(testFilePath + "cfzap.yaml")
+	assert.Nil(t, err, "the option should be created.")
+	assert.Nil(t, warnings)
+	assert.Equal(t, testFilePath+"cfzap.yaml", option.FilePath)
+	assert.Equal(t, []string{"a.yaml", "b.yaml"}, option.Overlays)
+	assert.Equal(t, "CFZAP", option.EnvPrefix)
+
+	// the XML config is converted first.
+	flags = &configFlags{}
+	option, warnings, err = flags.configOption(testFilePath + "logback.xml")
+	assert.Nil(t, err, "the XML config should be converted.")
+	assert.NotEmpty(t, warnings)
+	assert.Equal(t, "yaml", option.FileExt)
+	assert.NotEmpty(t, option.Data)
+
+	flags = &configFlags{profile: "prod"}
+	_, _, err = flags.configOption(testFilePath + "logback.xml")
+	assert.NotNil(t, err, "the profile is not supported for XML config.")
+}
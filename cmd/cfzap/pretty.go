@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cfzap "cfzap/src"
+)
+
+// stdin is the input of 'pretty' when no file is given, it's replaced in tests.
+var stdin io.Reader = os.Stdin
+
+// followInterval is the interval to check the log file for new lines and rotation.
+const followInterval = 200 * time.Millisecond
+
+// runPretty renders the JSON lines written by a cfzap appender as colored console lines.
+// the keys of the lines are taken from the encoderConfig of the appender in the config file.
+func runPretty(args []string, stdout io.Writer, stderr io.Writer) int {
+	var flags configFlags
+	set := newConfigFlagSet(lookupCommand("pretty"), stderr, &flags)
+	config := set.String("config", "", "the config `file` of the appender writing the lines, "+
+		"the keys of zap.NewProductionEncoderConfig() are used if it's not given")
+	appenderName := set.String("appender", "", "the `name` of the JSON appender in the config file, it's the one "+
+		"writing to the log file, or the first JSON appender by default")
+	levelName := set.String("level", "debug", "skip the lines below the `level`")
+	var greps stringList
+	set.Var(&greps, "grep", "show only the lines matching the `pattern`, 'key=regexp' matches the field, "+
+		"and 'regexp' matches the whole line. it can be repeated, and all of them must match")
+	follow := set.Bool("f", false, "follow the log file as it grows and is rotated")
+	tail := set.Int("n", -1, "render only the last `number` of lines existing in the log file, all by default")
+	color := set.String("color", "auto", "colorize the lines: 'auto', 'always' or 'never'")
+	timeLayout := set.String("time-layout", "2006-01-02T15:04:05.000Z0700",
+		"the `layout` of Go to render the time written in number")
+
+	files, ok, code := parseArgs(set, args, 0, 1)
+	if !ok {
+		return code
+	}
+	file := ""
+	if len(files) > 0 && files[0] != "-" {
+		file = files[0]
+	}
+
+	r := &renderer{encoder: defaultEncoder, timeLayout: *timeLayout, location: time.Local}
+	if *config != "" {
+		encoder, err := prettyEncoder(&flags, *config, *appenderName, file, stderr)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", *config, err)
+			return exitProblem
+		}
+		r.encoder = encoder
+	}
+
+	if err := r.minLevel.UnmarshalText([]byte(strings.ToLower(*levelName))); err != nil {
+		fmt.Fprintf(stderr, "cfzap pretty: %v\n", err)
+		return exitUsage
+	}
+	for _, grep := range greps {
+		filter, err := parseFilter(grep)
+		if err != nil {
+			fmt.Fprintf(stderr, "cfzap pretty: %v\n", err)
+			return exitUsage
+		}
+		r.filters = append(r.filters, filter)
+	}
+	switch *color {
+	case "always":
+		r.color = true
+	case "never":
+	case "auto":
+		r.color = isTerminal(stdout) && os.Getenv("NO_COLOR") == ""
+	default:
+		fmt.Fprintf(stderr, "cfzap pretty: invalid color [%s], it should be one of [auto, always, never]\n", *color)
+		return exitUsage
+	}
+
+	var f *follower
+	if file == "" {
+		f = newStreamFollower(stdin)
+	} else {
+		var err error
+		if f, err = openFollower(file, followInterval); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", file, err)
+			return exitProblem
+		}
+		defer func() { _ = f.close() }()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := bufio.NewWriter(stdout)
+	defer func() { _ = w.Flush() }()
+
+	if err := pretty(ctx, f, r, w, *tail, *follow && file != ""); err != nil && !errors.Is(err, context.Canceled) {
+		name := file
+		if name == "" {
+			name = "stdin"
+		}
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return exitProblem
+	}
+
+	return exitOK
+}
+
+// pretty renders the lines read by the follower to w. only the last tail lines existing are rendered
+// if tail is not negative. it follows the file after the existing lines if follow is true, until ctx is done.
+func pretty(ctx context.Context, f *follower, r *renderer, w *bufio.Writer, tail int, follow bool) error {
+	write := func(line []byte) {
+		if text, ok := r.render(line); ok {
+			_, _ = w.WriteString(text + "\n")
+		}
+		// the lines of a stream are rendered as soon as they are read.
+		if f.reader.Buffered() == 0 {
+			_ = w.Flush()
+		}
+	}
+
+	// the existing lines of the file.
+	var last [][]byte
+	for {
+		line, err := f.next(ctx, false)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if tail < 0 {
+			write(line)
+			continue
+		}
+		if last = append(last, line); len(last) > tail {
+			last = last[1:]
+		}
+	}
+	for _, line := range last {
+		write(line)
+	}
+
+	if !follow {
+		return nil
+	}
+
+	for {
+		line, err := f.next(ctx, true)
+		if err != nil {
+			return err
+		}
+		write(line)
+	}
+}
+
+// prettyEncoder returns the encoderConfig of the JSON appender in the config file. the appender is the one named
+// appenderName, or the one writing to file, or the first JSON appender.
+func prettyEncoder(flags *configFlags, config string, appenderName string, file string,
+	stderr io.Writer) (cfzap.EncoderDescription, error) {
+	option, warnings, err := flags.configOption(config)
+	printWarnings(stderr, config, warnings)
+	if err != nil {
+		return cfzap.EncoderDescription{}, err
+	}
+
+	descriptions, err := cfzap.DescribeLoggers(option)
+	if len(descriptions) == 0 {
+		return cfzap.EncoderDescription{}, err
+	}
+	if err != nil {
+		// the appender may be resolved still.
+		fmt.Fprintf(stderr, "%s: %v\n", config, err)
+	}
+
+	var appenders []cfzap.AppenderDescription
+	for _, description := range descriptions {
+		appenders = append(appenders, description.Appenders...)
+	}
+
+	appender, err := pickAppender(appenders, appenderName, file)
+	if err != nil {
+		return cfzap.EncoderDescription{}, err
+	}
+	if !strings.EqualFold(appender.EncoderType, "json") {
+		return cfzap.EncoderDescription{}, fmt.Errorf("appender [%s] writes %s lines, not JSON", appender.Name,
+			appender.EncoderType)
+	}
+
+	return appender.Encoder, nil
+}
+
+// pickAppender returns the appender named appenderName, or the one writing to file, or the first JSON appender.
+func pickAppender(appenders []cfzap.AppenderDescription, appenderName string, file string) (
+	cfzap.AppenderDescription, error) {
+	if appenderName != "" {
+		for _, appender := range appenders {
+			if appender.Name == appenderName {
+				return appender, nil
+			}
+		}
+		return cfzap.AppenderDescription{}, fmt.Errorf("appender [%s] is not used by any logger", appenderName)
+	}
+
+	if file != "" {
+		path, _ := filepath.Abs(file)
+		for _, appender := range appenders {
+			if appender.File == nil {
+				continue
+			}
+			if filename, err := filepath.Abs(filepath.FromSlash(appender.File.Filename)); err == nil && filename == path {
+				return appender, nil
+			}
+		}
+	}
+
+	for _, appender := range appenders {
+		if strings.EqualFold(appender.EncoderType, "json") {
+			return appender, nil
+		}
+	}
+
+	return cfzap.AppenderDescription{}, errors.New("no JSON appender is used by any logger")
+}
+
+// isTerminal checks to see if w is a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cfzap "cfzap/src"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPretty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfzap-pretty-test")
+	assert.Nil(t, err, "fail to create temp dir.")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// the keys of the JSON appender in cfzap.yaml.
+	file := filepath.Join(dir, "test.log")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(
+		`{"LEVEL":"DEBUG","TIME":"2021-06-01 08:00:00.000","MSG":"one"}`+"\n"+
+			`{"LEVEL":"WARN","TIME":"2021-06-01 08:00:01.000","MSG":"two","user":"u-1"}`+"\n"+
+			`{"LEVEL":"ERROR","TIME":"2021-06-01 08:00:02.000","MSG":"three","user":"u-2"}`+"\n"), 0644))
+
+	code, stdout, stderr := runCommand("pretty", "--config", testFilePath+"cfzap.yaml", file)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "2021-06-01 08:00:00.000 DEBUG one\n2021-06-01 08:00:01.000 WARN  two user=u-1\n"+
+		"2021-06-01 08:00:02.000 ERROR three user=u-2\n", stdout)
+
+	code, stdout, _ = runCommand("pretty", "--config", testFilePath+"cfzap.yaml", "--level", "warn",
+		"--grep", "user=2$", file)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "2021-06-01 08:00:02.000 ERROR three user=u-2\n", stdout)
+
+	code, stdout, _ = runCommand("pretty", "--config", testFilePath+"cfzap.yaml", "-n", "1", "--color", "always", file)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, colorGray+"2021-06-01 08:00:02.000"+colorReset+" "+colorRed+"ERROR"+colorReset+" three "+
+		colorGray+"user="+colorReset+"u-2\n", stdout)
+
+	// the console appender writes no JSON.
+	code, _, stderr = runCommand("pretty", "--config", testFilePath+"cfzap.yaml", "--appender", "appender-stdout", file)
+	assert.Equal(t, exitProblem, code)
+	assert.Contains(t, stderr, "appender [appender-stdout] writes console lines, not JSON")
+
+	code, _, stderr = runCommand("pretty", filepath.Join(dir, "missing.log"))
+	assert.Equal(t, exitProblem, code)
+	assert.Contains(t, stderr, "missing.log")
+
+	code, _, stderr = runCommand("pretty", "--level", "loud", file)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unrecognized level")
+}
+
+func TestPrettyStdin(t *testing.T) {
+	defer func() { stdin = os.Stdin }()
+	stdin = strings.NewReader(`{"level":"info","ts":0,"msg":"hello"}` + "\nnot json\n")
+
+	code, stdout, stderr := runCommand("pretty", "--time-layout", "2006", "-")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, " INFO  hello\nnot json\n")
+}
+
+func TestPickAppender(t *testing.T) {
+	appenders := []cfzap.AppenderDescription{
+		{Name: "console", EncoderType: "console"},
+		{Name: "json", EncoderType: "json"},
+		{Name: "file", EncoderType: "json", File: &cfzap.FileDescription{Filename: "logs/app.log"}},
+	}
+
+	appender, err := pickAppender(appenders, "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "json", appender.Name, "the first JSON appender should be picked.")
+
+	appender, _ = pickAppender(appenders, "", "./logs/../logs/app.log")
+	assert.Equal(t, "file", appender.Name, "the appender writing to the file should be picked.")
+
+	appender, _ = pickAppender(appenders, "console", "")
+	assert.Equal(t, "console", appender.Name)
+
+	_, err = pickAppender(appenders, "missing", "")
+	assert.Equal(t, "appender [missing] is not used by any logger", err.Error())
+
+	_, err = pickAppender(appenders[:1], "", "")
+	assert.Equal(t, "no JSON appender is used by any logger", err.Error())
+}
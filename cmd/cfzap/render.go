@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	cfzap "cfzap/src"
+	"go.uber.org/zap/zapcore"
+)
+
+// the ANSI colors used to render the lines.
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorGray    = "\x1b[90m"
+)
+
+// levelColors are the colors of the levels, same as the 'color' level encoder of zap.
+var levelColors = map[zapcore.Level]string{
+	zapcore.DebugLevel:  colorMagenta,
+	zapcore.InfoLevel:   colorBlue,
+	zapcore.WarnLevel:   colorYellow,
+	zapcore.ErrorLevel:  colorRed,
+	zapcore.DPanicLevel: colorRed,
+	zapcore.PanicLevel:  colorRed,
+	zapcore.FatalLevel:  colorRed,
+}
+
+// ansiEscape matches the colors written by the 'color' level encoder.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// defaultEncoder is the encoderConfig of the JSON lines when no config is given,
+// the keys are the ones of zap.NewProductionEncoderConfig().
+var defaultEncoder = cfzap.EncoderDescription{
+	MessageKey:    "msg",
+	LevelKey:      "level",
+	TimeKey:       "ts",
+	NameKey:       "logger",
+	CallerKey:     "caller",
+	StacktraceKey: "stacktrace",
+	EncodeTime:    "epoch",
+}
+
+// field is a field of a JSON line, in the order of the line.
+type field struct {
+	key   string
+	value json.RawMessage
+}
+
+// fieldFilter is a filter of the lines given by '--grep'.
+type fieldFilter struct {
+	// the key of the field, the whole line is matched when it's empty.
+	key     string
+	pattern *regexp.Regexp
+}
+
+// renderer renders the JSON lines written by an appender as colored console lines.
+type renderer struct {
+	// the encoderConfig of the appender, the keys are used to find the fields.
+	encoder cfzap.EncoderDescription
+	// the layout of Go to render the time in number.
+	timeLayout string
+	// the location of the time in number.
+	location *time.Location
+	color    bool
+	// the lines below the level are skipped.
+	minLevel zapcore.Level
+	// the lines not matching all filters are skipped.
+	filters []fieldFilter
+}
+
+// parseFilter parses the filter given by '--grep', 'key=pattern' matches the value of the field,
+// and 'pattern' matches the whole line.
+func parseFilter(s string) (fieldFilter, error) {
+	var filter fieldFilter
+	expression := s
+	if i := strings.Index(s, "="); i > 0 {
+		filter.key, expression = s[:i], s[i+1:]
+	}
+
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return filter, fmt.Errorf("invalid pattern [%s]: %v", s, err)
+	}
+	filter.pattern = pattern
+
+	return filter, nil
+}
+
+// render renders the line, it returns false if the line is skipped by the level or the filters.
+// the lines which are not JSON objects are rendered as they are, only the filters on the whole line apply to them.
+func (r *renderer) render(line []byte) (string, bool) {
+	fields, err := parseFields(line)
+	if err != nil {
+		for _, filter := range r.filters {
+			if filter.key != "" || !filter.pattern.Match(line) {
+				return "", false
+			}
+		}
+		return string(line), true
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values[f.key] = fieldText(f.value)
+	}
+
+	level, hasLevel := r.level(values)
+	if hasLevel && level < r.minLevel {
+		return "", false
+	}
+	for _, filter := range r.filters {
+		if filter.key == "" {
+			if !filter.pattern.Match(line) {
+				return "", false
+			}
+			continue
+		}
+		if value, ok := values[filter.key]; !ok || !filter.pattern.MatchString(value) {
+			return "", false
+		}
+	}
+
+	var parts []string
+	known := map[string]bool{}
+	take := func(key string) (string, bool) {
+		if key == "" {
+			return "", false
+		}
+		value, ok := values[key]
+		known[key] = ok
+		return value, ok
+	}
+
+	if ts, ok := take(r.encoder.TimeKey); ok {
+		parts = append(parts, r.paint(colorGray, r.timeText(ts, fieldsValue(fields, r.encoder.TimeKey))))
+	}
+	if text, ok := take(r.encoder.LevelKey); ok {
+		color := ""
+		if hasLevel {
+			color = levelColors[level]
+		}
+		text = strings.ToUpper(ansiEscape.ReplaceAllString(text, ""))
+		parts = append(parts, r.paint(color, fmt.Sprintf("%-5s", text)))
+	}
+	if name, ok := take(r.encoder.NameKey); ok {
+		parts = append(parts, r.paint(colorCyan, name))
+	}
+	if caller, ok := take(r.encoder.CallerKey); ok {
+		parts = append(parts, r.paint(colorGray, caller))
+	}
+	if function, ok := take(r.encoder.FunctionKey); ok {
+		parts = append(parts, r.paint(colorGray, function))
+	}
+	if message, ok := take(r.encoder.MessageKey); ok {
+		parts = append(parts, message)
+	}
+	stacktrace, hasStacktrace := take(r.encoder.StacktraceKey)
+
+	for _, f := range fields {
+		if known[f.key] {
+			continue
+		}
+		// the strings are quoted when they cannot be told apart from the other fields.
+		value := fieldText(f.value)
+		if bytes.HasPrefix(f.value, []byte(`"`)) && (value == "" || strings.ContainsAny(value, " \t\n\"")) {
+			value = strconv.Quote(value)
+		}
+		parts = append(parts, r.paint(colorGray, f.key+"=")+value)
+	}
+
+	text := strings.Join(parts, " ")
+	if hasStacktrace {
+		text += "\n" + r.paint(colorRed, stacktrace)
+	}
+
+	return text, true
+}
+
+// level returns the level of the line, it returns false if the line has no level or the level is unknown.
+func (r *renderer) level(values map[string]string) (zapcore.Level, bool) {
+	text, ok := values[r.encoder.LevelKey]
+	if r.encoder.LevelKey == "" || !ok {
+		return zapcore.InfoLevel, false
+	}
+
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(ansiEscape.ReplaceAllString(text, "")))); err != nil {
+		return zapcore.InfoLevel, false
+	}
+
+	return level, true
+}
+
+// timeText returns the time to render, the time in number is decoded according to the time encoder.
+// the time in string, such as ISO8601, is rendered as it is.
+func (r *renderer) timeText(text string, raw json.RawMessage) string {
+	number, err := strconv.ParseFloat(string(bytes.TrimSpace(raw)), 64)
+	if err != nil {
+		return text
+	}
+
+	var t time.Time
+	switch strings.ToLower(r.encoder.EncodeTime) {
+	case "millis":
+		t = time.Unix(0, int64(number*float64(time.Millisecond)))
+	case "nanos":
+		t = time.Unix(0, int64(number))
+	default:
+		// zap falls back to the epoch encoder for the unknown time encoders.
+		seconds, fraction := math.Modf(number)
+		t = time.Unix(int64(seconds), int64(fraction*float64(time.Second)))
+	}
+
+	return t.In(r.location).Format(r.timeLayout)
+}
+
+// paint returns the text in color, or the text itself if the color is disabled.
+func (r *renderer) paint(color string, text string) string {
+	if !r.color || color == "" {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// parseFields parses the JSON object into fields, the order of the fields is kept.
+func parseFields(line []byte) ([]field, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("the line is not a JSON object")
+	}
+
+	var fields []field
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field{key: token.(string), value: value})
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// fieldsValue returns the raw value of the field by key.
+func fieldsValue(fields []field, key string) json.RawMessage {
+	for _, f := range fields {
+		if f.key == key {
+			return f.value
+		}
+	}
+
+	return nil
+}
+
+// fieldText returns the text of the value, the string is unquoted and the others are the JSON text.
+func fieldText(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+
+	return string(value)
+}
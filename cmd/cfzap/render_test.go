@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	cfzap "cfzap/src"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRender(t *testing.T) {
+	r := &renderer{encoder: defaultEncoder, timeLayout: "15:04:05.000", location: time.UTC}
+
+	text, ok := r.render([]byte(`{"level":"info","ts":1622505600.5,"logger":"db","caller":"db/pool.go:12",` +
+		`"msg":"connected","host":"db-1","took":0.25,"tags":["a","b"],"note":"two words"}`))
+	assert.True(t, ok)
+	assert.Equal(t, `00:00:00.500 INFO  db db/pool.go:12 connected host=db-1 took=0.25 tags=["a","b"] `+
+		`note="two words"`, text)
+
+	// the stacktrace is written below the line.
+	text, _ = r.render([]byte(`{"level":"error","msg":"failed","stacktrace":"main.main\n\tmain.go:10"}`))
+	assert.Equal(t, "ERROR failed\nmain.main\n\tmain.go:10", text)
+
+	// the lines which are not JSON are written as they are.
+	text, ok = r.render([]byte("panic: oops"))
+	assert.True(t, ok)
+	assert.Equal(t, "panic: oops", text)
+
+	r.color = true
+	text, _ = r.render([]byte(`{"level":"warn","msg":"slow","ms":12}`))
+	assert.Equal(t, colorYellow+"WARN "+colorReset+" slow "+colorGray+"ms="+colorReset+"12", text)
+}
+
+func TestRenderWithEncoder(t *testing.T) {
+	r := &renderer{timeLayout: time.RFC3339, location: time.UTC, encoder: cfzap.EncoderDescription{
+		MessageKey: "MSG", LevelKey: "LEVEL", TimeKey: "TIME", NameKey: "NAME", EncodeTime: "millis"}}
+
+	text, _ := r.render([]byte(`{"LEVEL":"\u001b[34mINFO\u001b[0m","TIME":1622505600000,"MSG":"ok","msg":"field"}`))
+	assert.Equal(t, "2021-06-01T00:00:00Z INFO  ok msg=field", text)
+
+	// the time in string is kept.
+	text, _ = r.render([]byte(`{"TIME":"2021-06-01T08:00:00.000+0800","MSG":"ok"}`))
+	assert.Equal(t, "2021-06-01T08:00:00.000+0800 ok", text)
+
+	r.encoder.EncodeTime = "nanos"
+	text, _ = r.render([]byte(`{"TIME":1622505600000000000}`))
+	assert.Equal(t, "2021-06-01T00:00:00Z", text)
+}
+
+func TestRenderFilters(t *testing.T) {
+	r := &renderer{encoder: defaultEncoder, minLevel: zapcore.WarnLevel}
+
+	_, ok := r.render([]byte(`{"level":"info","msg":"skipped"}`))
+	assert.False(t, ok, "the line below the level should be skipped.")
+	_, ok = r.render([]byte(`{"level":"error","msg":"kept"}`))
+	assert.True(t, ok)
+	_, ok = r.render([]byte(`{"level":"unknown","msg":"kept"}`))
+	assert.True(t, ok, "the line of unknown level should be kept.")
+
+	for _, grep := range []string{"user=^u-1", "timeout"} {
+		filter, err := parseFilter(grep)
+		assert.Nil(t, err)
+		r.filters = append(r.filters, filter)
+	}
+	_, ok = r.render([]byte(`{"level":"error","msg":"timeout","user":"u-12"}`))
+	assert.True(t, ok, "the line matching all filters should be kept.")
+	_, ok = r.render([]byte(`{"level":"error","msg":"timeout","user":"u-2"}`))
+	assert.False(t, ok, "the line not matching the field should be skipped.")
+	_, ok = r.render([]byte(`{"level":"error","msg":"refused","user":"u-1"}`))
+	assert.False(t, ok, "the line not matching the pattern should be skipped.")
+	_, ok = r.render([]byte(`timeout`))
+	assert.False(t, ok, "the field filter never matches the line which is not JSON.")
+
+	_, err := parseFilter("msg=(")
+	assert.NotNil(t, err, "the pattern is invalid.")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	cfzap "cfzap/src"
+)
+
+// runValidate checks the config files in strict mode without creating any writer or directory,
+// and prints all problems found.
+func runValidate(args []string, stdout io.Writer, stderr io.Writer) int {
+	var flags configFlags
+	files, ok, code := parseArgs(newConfigFlagSet(lookupCommand("validate"), stderr, &flags), args, 1, 0)
+	if !ok {
+		return code
+	}
+
+	code = exitOK
+	for _, file := range files {
+		if !validateFile(file, &flags, stdout, stderr) {
+			code = exitProblem
+		}
+	}
+
+	return code
+}
+
+// validateFile checks the config file and prints the problems, it returns true if the file is valid.
+func validateFile(file string, flags *configFlags, stdout io.Writer, stderr io.Writer) bool {
+	option, warnings, err := flags.configOption(file)
+	printWarnings(stderr, file, warnings)
+	if err != nil {
+		fmt.Fprintf(stdout, "%s: %v\n", file, err)
+		return false
+	}
+
+	problems := cfzap.Validate(option)
+	if len(problems) == 0 {
+		// the rules of Validate() don't cover the loggers which cannot be created at all,
+		// such as the config without any logger.
+		if _, err := cfzap.DescribeLoggers(option); err != nil {
+			problems = describeProblems(err)
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintf(stdout, "%s: ok\n", file)
+		return true
+	}
+
+	printProblems(stdout, file, problems)
+
+	return false
+}
+
+// printProblems prints the problems of the config file and their count.
+func printProblems(w io.Writer, file string, problems []cfzap.Problem) {
+	// the messages contain the keys already.
+	for _, problem := range problems {
+		fmt.Fprintf(w, "%s: %s\n", file, problem.Message)
+	}
+	fmt.Fprintf(w, "%s: %d problem(s) found\n", file, len(problems))
+}
+
+// describeProblems returns the problems listed by the error of cfzap.DescribeLoggers().
+func describeProblems(err error) []cfzap.Problem {
+	var configErrors *cfzap.ConfigErrors
+	if !errors.As(err, &configErrors) {
+		return []cfzap.Problem{{Message: err.Error(), Err: err}}
+	}
+
+	problems := make([]cfzap.Problem, len(configErrors.Errors))
+	for i, e := range configErrors.Errors {
+		problems[i] = cfzap.Problem{Message: e.Error(), Err: e}
+	}
+
+	return problems
+}
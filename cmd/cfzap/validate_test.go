@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	code, stdout, _ := runCommand("validate", testFilePath+"cfzap.yaml", testFilePath+"zap_config.json")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, testFilePath+"cfzap.yaml: ok\n"+testFilePath+"zap_config.json: ok\n", stdout)
+
+	// all problems are printed with their keys.
+	code, stdout, _ = runCommand("validate", testFilePath+"strict_config.yaml")
+	assert.Equal(t, exitProblem, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Equal(t, 8, len(lines))
+	assert.Contains(t, lines[0], "[appender-file.encoderType]")
+	assert.Equal(t, testFilePath+"strict_config.yaml: 7 problem(s) found", lines[7])
+
+	code, stdout, _ = runCommand("validate", testFilePath+"no_file.yaml")
+	assert.Equal(t, exitProblem, code)
+	assert.Contains(t, stdout, "no_file.yaml: ")
+
+	// the warnings of converting XML config are not problems.
+	code, stdout, stderr := runCommand("validate", testFilePath+"log4j2.xml")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, testFilePath+"log4j2.xml: ok\n", stdout)
+	assert.Contains(t, stderr, "log4j2.xml: warning: ")
+
+	code, _, stderr = runCommand("validate")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "Usage: cfzap validate")
+}
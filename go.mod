@@ -3,8 +3,12 @@ module cfzap
 go 1.16
 
 require (
+	github.com/fsnotify/fsnotify v1.4.9
+	github.com/pelletier/go-toml v1.9.3
+	github.com/spf13/cast v1.3.1
 	github.com/spf13/viper v1.8.1
 	github.com/stretchr/testify v1.7.0
 	go.uber.org/zap v1.17.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
+	gopkg.in/yaml.v2 v2.4.0
 )
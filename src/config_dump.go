@@ -0,0 +1,285 @@
+package cfzap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DumpEffectiveConfig serializes the config actually used by the loggers in the registry, in the same format
+// as the config file. It's the result after the defaults, env overrides, profiles and includes are applied,
+// and it reflects the levels changed at runtime too. format is one of 'json', 'toml', 'yaml' and 'yml'.
+// The values are expanded already, so '${' in them is written as '$${' to be read as it is again.
+//
+// When there's only one logger, its appenders, options and levels are the top level ones,
+// so the output can be used to create the same logger again. Otherwise, each logger is an entry in 'loggers'
+// section, named by the name given to Register(), or the config file name and logger name,
+// with '.' replaced by '-'. The sections with the same name but different content are suffixed by '-2', '-3' and so on.
+//
+// It returns error if there's no logger in the registry.
+func (r *Registry) DumpEffectiveConfig(format string) ([]byte, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var entries []*registryEntry
+	for _, entry := range r.entries() {
+		// the entry closed by Shutdown() has no appender.
+		if entry.logger != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("there is no logger in the registry")
+	}
+
+	descriptions := make([]*LoggerDescription, len(entries))
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		descriptions[i] = entry.description()
+		names[i] = dumpLoggerName(entry.name)
+	}
+
+	top := -1
+	if len(entries) == 1 {
+		top = 0
+	}
+
+	return dumpLoggers(descriptions, names, top, format)
+}
+
+// ConvertConfig reads the config specified by configOption, and serializes it in the given format, which is one of
+// 'json', 'toml', 'yaml' and 'yml'. It's the result after the defaults, env overrides, profiles and includes are
+// applied, and zap.Config documents are converted, so the output can be read without the other files.
+// '${' in the values is written as '$${' as DumpEffectiveConfig() does.
+// All loggers defined in the config are kept, the top level logger at the top level and the others
+// in 'loggers' section. Only the sections used by the loggers are written.
+//
+// No writer is opened and no directory is created. It returns error if any appender fails to resolve,
+// since it would be missing in the output. Note, the reader in configOption is consumed.
+func ConvertConfig(configOption *ConfigOption, format string) ([]byte, error) {
+	if configOption == nil { // using default value if it is not provided.
+		configOption = NewConfigOption()
+	}
+
+	descriptions, err := DescribeLoggers(configOption)
+	if err != nil {
+		return nil, err
+	}
+
+	// the logger names are kept, the name with '.' is resolved as GetNamedLogger() does.
+	names := make([]string, len(descriptions))
+	for i, description := range descriptions {
+		names[i] = description.Name
+	}
+
+	// the top level logger comes first if it's defined.
+	top := -1
+	if descriptions[0].Name == "" {
+		top = 0
+	}
+
+	return dumpLoggers(descriptions, names, top, format)
+}
+
+// dumpLoggers serializes the loggers in the given format. the logger at index top is the top level logger,
+// there's no top level logger if it's -1. the others are in 'loggers' section by the names.
+func dumpLoggers(descriptions []*LoggerDescription, names []string, top int, format string) ([]byte, error) {
+	dumper := &configDumper{settings: make(map[string]interface{})}
+
+	loggers := make(map[string]interface{})
+	for i, description := range descriptions {
+		if i == top {
+			dumper.addLogger(dumper.settings, description)
+			continue
+		}
+
+		logger := make(map[string]interface{})
+		dumper.addLogger(logger, description)
+		loggers[uniqueKey(loggers, names[i])] = logger
+	}
+	if len(loggers) > 0 {
+		dumper.settings[loggersSection] = loggers
+	}
+
+	// the values are expanded already, they must not be expanded again when the output is read.
+	return encodeSettings(escapeVariables(dumper.settings).(map[string]interface{}), format)
+}
+
+// configDumper builds the settings of the effective config.
+type configDumper struct {
+	// the settings in the format of config file.
+	settings map[string]interface{}
+}
+
+// addLogger adds the appenders, options and levels of the logger into settings,
+// and the sections used by the appenders into the top level settings.
+func (d *configDumper) addLogger(logger map[string]interface{}, description *LoggerDescription) {
+	appenders := make([]interface{}, len(description.Appenders))
+	for i, appender := range description.Appenders {
+		appenders[i] = d.addSection(appender.Name, d.appenderSettings(appender))
+	}
+	logger["appenders"] = appenders
+
+	options := map[string]interface{}{
+		"caller":      description.Options.Caller,
+		"development": description.Options.Development,
+	}
+	if len(description.Options.Fields) > 0 {
+		fields := make(map[string]interface{}, len(description.Options.Fields))
+		for k, v := range description.Options.Fields {
+			fields[k] = v
+		}
+		options["fields"] = fields
+	}
+	logger["options"] = options
+
+	if len(description.Levels) > 0 {
+		values := make(map[string]interface{}, len(description.Levels))
+		for name, level := range description.Levels {
+			values[name] = level.String()
+		}
+		logger["levels"] = values
+	}
+}
+
+// appenderSettings returns the settings of the appender section,
+// the target and encoderConfig sections are added into the settings.
+func (d *configDumper) appenderSettings(description AppenderDescription) map[string]interface{} {
+	target := description.TargetKind
+	if description.TargetKind == TargetFile {
+		file := description.File
+		target = d.addSection(description.Target, map[string]interface{}{
+			"filename":   file.Filename,
+			"maxSize":    file.MaxSize,
+			"maxAge":     file.MaxAge,
+			"maxBackups": file.MaxBackups,
+			"localTime":  file.LocalTime,
+			"compress":   file.Compress,
+		})
+	}
+
+	settings := map[string]interface{}{
+		"target":        target,
+		"encoderType":   description.EncoderType,
+		"logLevel":      description.Level.String(),
+		"encoderConfig": d.addSection(description.EncoderConfig, encoderConfigSettings(description.Encoder)),
+	}
+	// the default threshold passes all levels, it's omitted.
+	if description.Threshold != zapcore.DebugLevel {
+		settings["threshold"] = description.Threshold.String()
+	}
+
+	return settings
+}
+
+// encoderConfigSettings returns the settings of the encoderConfig section.
+// the keys not set are omitted, unless zap uses a default value for them.
+func encoderConfigSettings(encoder EncoderDescription) map[string]interface{} {
+	settings := make(map[string]interface{})
+
+	keys := map[string]string{
+		"messageKey":    encoder.MessageKey,
+		"levelKey":      encoder.LevelKey,
+		"timeKey":       encoder.TimeKey,
+		"nameKey":       encoder.NameKey,
+		"callerKey":     encoder.CallerKey,
+		"functionKey":   encoder.FunctionKey,
+		"stacktraceKey": encoder.StacktraceKey,
+	}
+	for k, v := range keys {
+		if v != "" {
+			settings[k] = v
+		}
+	}
+
+	// zap uses the defaults when they are empty.
+	settings["lineEnding"] = encoder.LineEnding
+	if encoder.LineEnding == "" {
+		settings["lineEnding"] = zapcore.DefaultLineEnding
+	}
+	settings["consoleSeparator"] = encoder.ConsoleSeparator
+	if encoder.ConsoleSeparator == "" {
+		settings["consoleSeparator"] = "\t"
+	}
+
+	// the unrecognized values are replaced by the ones zap falls back to.
+	if encoder.EncodeLevel != "" {
+		settings["encodeLevel"] = effectiveEncoder(encoder.EncodeLevel, levelEncoders, "lowercase")
+	}
+	if s := encoder.EncodeTime; s != "" {
+		if strings.Index(s, "%") == 0 { // customized format starts from '%'
+			settings["encodeTime"] = s
+		} else {
+			settings["encodeTime"] = effectiveEncoder(s, timeEncoders, "epoch")
+		}
+	}
+	if encoder.EncodeDuration != "" {
+		settings["encodeDuration"] = effectiveEncoder(encoder.EncodeDuration, durationEncoder, "seconds")
+	}
+	if encoder.EncodeName != "" {
+		settings["encodeName"] = effectiveEncoder(encoder.EncodeName, nameEncoders, "full")
+	}
+	// encodeCaller is always set.
+	settings["encodeCaller"] = effectiveEncoder(encoder.EncodeCaller, callerEncoders, "short")
+
+	return settings
+}
+
+// effectiveEncoder returns the lower case value if it's one of the known values, otherwise it returns fallback.
+func effectiveEncoder(value string, known []string, fallback string) string {
+	if s := strings.ToLower(strings.TrimSpace(value)); StringInArray(s, known) {
+		return s
+	}
+
+	return fallback
+}
+
+// addSection adds the section into the settings by name, and returns the name used.
+// the name is suffixed when there's a different section or a top level key with the same name.
+func (d *configDumper) addSection(name string, section map[string]interface{}) string {
+	for i := 1; ; i++ {
+		key := name
+		if i > 1 {
+			key = fmt.Sprintf("%s-%d", name, i)
+		}
+
+		if StringInArray(key, topLevelKeys) {
+			continue
+		}
+
+		existing, ok := d.settings[key]
+		if !ok {
+			d.settings[key] = section
+			return key
+		}
+		if reflect.DeepEqual(existing, section) {
+			return key
+		}
+	}
+}
+
+// uniqueKey returns the name suffixed by '-2', '-3' and so on if it's used in settings already.
+func uniqueKey(settings map[string]interface{}, name string) string {
+	key := name
+	for i := 2; ; i++ {
+		if _, ok := settings[key]; !ok {
+			return key
+		}
+		key = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// dumpLoggerName returns the name of the logger in 'loggers' section.
+// '.' is the key delimiter of viper, so it's replaced by '-'.
+func dumpLoggerName(loggerName string) string {
+	name := strings.Trim(strings.ReplaceAll(loggerName, ".", "-"), "-")
+	if name == "" {
+		return "logger"
+	}
+
+	return name
+}
@@ -0,0 +1,197 @@
+package cfzap
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDumpEffectiveConfig(t *testing.T) {
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	_, err := r.DumpEffectiveConfig("yaml")
+	assert.NotNil(t, err, "there is no logger to dump.")
+
+	dir := t.TempDir()
+
+	data := []byte(`
+appenders: [appender-stdout, appender-file]
+options:
+  caller: true
+levels:
+  db: warn
+appender-stdout:
+  target: STDOUT
+  encoderType: Console
+  encoderConfig: encoderConfig
+appender-file:
+  target: lumberjack
+  logLevel: debug
+  encoderConfig: encoderConfig
+lumberjack:
+  filename: ` + filepath.ToSlash(filepath.Join(dir, "test.log")) + `
+  maxSize: 1
+encoderConfig:
+  messageKey: MSG
+  encodeLevel: Capital
+  encodeTime: unknown
+`)
+	option := NewConfigOption(WithData(data, "yaml"))
+	logger, err := r.GetLogger(option)
+	assert.Nil(t, err, "fail to create logger.")
+
+	// the levels changed at runtime are dumped.
+	holder, _ := holderOf(logger)
+	holder.loadAppenders()["appender-file"].logLevel.SetLevel(zapcore.ErrorLevel)
+	holder.levels.SetLevel("db.pool", zapcore.DebugLevel)
+
+	dump, err := r.DumpEffectiveConfig("YAML")
+	assert.Nil(t, err, "fail to dump config.")
+
+	config := viper.New()
+	config.SetConfigType("yaml")
+	assert.Nil(t, config.ReadConfig(bytes.NewReader(dump)), "the dump should be valid yaml.")
+	assert.Equal(t, []interface{}{"appender-file", "appender-stdout"}, config.Get("appenders"))
+	assert.Equal(t, "stdout", config.GetString("appender-stdout.target"))
+	assert.Equal(t, "console", config.GetString("appender-stdout.encoderType"))
+	assert.Equal(t, "json", config.GetString("appender-file.encoderType"))
+	assert.Equal(t, "error", config.GetString("appender-file.logLevel"))
+	assert.Equal(t, 1, config.GetInt("lumberjack.maxSize"))
+	assert.Equal(t, "capital", config.GetString("encoderConfig.encodeLevel"))
+	assert.Equal(t, "epoch", config.GetString("encoderConfig.encodeTime"))
+	assert.Equal(t, "short", config.GetString("encoderConfig.encodeCaller"))
+	assert.True(t, config.GetBool("options.caller"))
+	assert.Equal(t, "debug", config.GetString("levels.db.pool"))
+
+	// the dump creates the same logger again.
+	expected, err := DescribeLogger(option, "")
+	assert.Nil(t, err, "fail to describe logger.")
+	actual, err := DescribeLogger(NewConfigOption(WithData(dump, "yaml")), "")
+	assert.Nil(t, err, "fail to describe the dump.")
+	assert.Equal(t, len(expected.Appenders), len(actual.Appenders))
+	assert.Equal(t, expected.Appenders[0].File, actual.Appenders[0].File)
+	assert.Equal(t, expected.Options, actual.Options)
+
+	for _, format := range []string{"json", "toml"} {
+		dump, err = r.DumpEffectiveConfig(format)
+		assert.Nil(t, err, "fail to dump config in "+format)
+
+		config = viper.New()
+		config.SetConfigType(format)
+		assert.Nil(t, config.ReadConfig(bytes.NewReader(dump)), "the dump should be valid "+format)
+		assert.Equal(t, "MSG", config.GetString("encoderConfig.messageKey"))
+	}
+
+	_, err = r.DumpEffectiveConfig("xml")
+	assert.Equal(t, "unsupported format [xml], it should be one of [json, toml, yaml, yml]", err.Error())
+}
+
+func TestDumpEffectiveConfigOfLoggers(t *testing.T) {
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	option := NewConfigOption(WithFileName("logger_config"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+	_, err := r.GetNamedLogger(option, "app")
+	assert.Nil(t, err, "fail to create logger app.")
+	_, err = r.Register("audit", cloneConfigOption(option))
+	assert.Nil(t, err, "fail to register logger audit.")
+
+	// the same section name with different content.
+	data := []byte(`
+appenders: [appender-stdout]
+appender-stdout:
+  target: stdout
+  encoderConfig: encoderConfig
+encoderConfig:
+  messageKey: message
+`)
+	_, err = r.GetLogger(NewConfigOption(WithData(data, "yaml")))
+	assert.Nil(t, err, "fail to create logger from data.")
+
+	dump, err := r.DumpEffectiveConfig("json")
+	assert.Nil(t, err, "fail to dump config.")
+
+	config := viper.New()
+	config.SetConfigType("json")
+	assert.Nil(t, config.ReadConfig(bytes.NewReader(dump)), "the dump should be valid json.")
+
+	assert.Equal(t, []interface{}{"appender-stdout"}, config.Get("loggers.audit.appenders"))
+	assert.Equal(t, []interface{}{"appender-stderr", "appender-stdout"}, config.Get("loggers.logger_config-app.appenders"))
+	assert.True(t, config.GetBool("loggers.logger_config-app.options.caller"))
+	assert.Equal(t, []interface{}{"appender-stdout-2"}, config.Get("loggers.cfzap.appenders"))
+	assert.Equal(t, "MSG", config.GetString("encoderConfig.messageKey"))
+	assert.Equal(t, "encoderConfig-2", config.GetString("appender-stdout-2.encoderConfig"))
+	assert.Equal(t, "message", config.GetString("encoderConfig-2.messageKey"))
+}
+
+func TestConvertConfig(t *testing.T) {
+	option := NewConfigOption(WithFileName("logger_config"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+	_, err := ConvertConfig(option, "json")
+	assert.NotNil(t, err, "the logger [broken] cannot be converted.")
+
+	data := []byte(`
+loggers:
+  web:
+    appenders: [appender-stdout]
+    levels:
+      http: warn
+appenders: [appender-stdout]
+options:
+  fields:
+    app: demo
+appender-stdout:
+  target: stdout
+  logLevel: Debug
+  encoderConfig: encoderConfig
+encoderConfig:
+  messageKey: MSG
+unused:
+  key: value
+`)
+	for _, format := range []string{"json", "toml", "yaml"} {
+		converted, err := ConvertConfig(NewConfigOption(WithData(data, "yaml")), format)
+		assert.Nil(t, err, "fail to convert config to "+format)
+
+		config := viper.New()
+		config.SetConfigType(format)
+		assert.Nil(t, config.ReadConfig(bytes.NewReader(converted)), "the config should be valid "+format)
+		assert.Equal(t, []interface{}{"appender-stdout"}, config.Get("appenders"))
+		assert.Equal(t, "demo", config.GetString("options.fields.app"))
+		assert.Equal(t, "debug", config.GetString("appender-stdout.logLevel"))
+		assert.Equal(t, "MSG", config.GetString("encoderConfig.messageKey"))
+		assert.Equal(t, "warn", config.GetString("loggers.web.levels.http"))
+		assert.False(t, config.IsSet("unused"), "the unused section should not be converted.")
+
+		assert.Nil(t, Validate(NewConfigOption(WithData(converted, format))), "the converted config should be valid.")
+	}
+}
+
+func TestConvertConfigEscapesVariables(t *testing.T) {
+	data := []byte(`
+appenders: [appender-stdout]
+options:
+  fields:
+    template: $${user}
+    price: $$${amount}
+appender-stdout:
+  target: stdout
+  encoderConfig: encoderConfig
+encoderConfig:
+  messageKey: MSG
+`)
+	for _, format := range []string{"json", "toml", "yaml"} {
+		converted, err := ConvertConfig(NewConfigOption(WithData(data, "yaml")), format)
+		assert.Nil(t, err, "fail to convert config to "+format)
+
+		// the converted config is read again with the same values.
+		description, err := DescribeLogger(NewConfigOption(WithData(converted, format)), "")
+		assert.Nil(t, err, "the converted config should be read again from "+format)
+		assert.Equal(t, map[string]string{"template": "${user}", "price": "$${amount}"}, description.Options.Fields)
+	}
+}
@@ -0,0 +1,39 @@
+package cfzap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v2"
+)
+
+// encodeFormats are the formats supported by encodeSettings().
+var encodeFormats = []string{"json", "toml", "yaml", "yml"}
+
+// encodeSettings serializes the settings in the given format, the keys are kept as they are.
+// the format is one of encodeFormats, it's not case sensitive.
+func encodeSettings(settings map[string]interface{}, format string) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "json":
+		data, err := json.MarshalIndent(settings, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		return append(data, '\n'), nil
+	case "toml":
+		tree, err := toml.TreeFromMap(settings)
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(tree.String()), nil
+	case "yaml", "yml":
+		return yaml.Marshal(settings)
+	default:
+		return nil, fmt.Errorf("unsupported format [%s], it should be one of [%s]", format,
+			strings.Join(encodeFormats, ", "))
+	}
+}
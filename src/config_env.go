@@ -0,0 +1,142 @@
+package cfzap
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// envKeyReplacer replaces the characters which are not allowed in environment variable names.
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// envName returns the environment variable name of the config key.
+// the key is converted to upper case, and '.' and '-' are replaced by '_', then it's prefixed by prefix and '_'.
+// for example, the key 'appender-file.logLevel' with prefix 'CFZAP' is 'CFZAP_APPENDER_FILE_LOGLEVEL'.
+func envName(prefix string, key string) string {
+	return strings.ToUpper(prefix + "_" + envKeyReplacer.Replace(key))
+}
+
+// applyEnvOverrides replaces the values in config with the environment variables.
+// the name of environment variable cannot be converted back to the key, so the keys are taken from config:
+// the keys present in config, and the known keys of the sections referred, that is the options of each logger,
+// the appender sections in the 'appenders' lists, and their lumberjack and encoderConfig sections.
+// note, '.' and '-' are both replaced by '_', so the keys such as 'a-b.c' and 'a.b-c' share the same variable.
+// the value of a list, such as 'appenders', is separated by ','.
+func applyEnvOverrides(config *viper.Viper, prefix string) error {
+	applied := make(map[string]bool)
+
+	// the lists of appenders may be overridden first, so the sections referred are taken after that.
+	if err := applyEnvKeys(config, prefix, config.AllKeys(), applied); err != nil {
+		return err
+	}
+
+	return applyEnvKeys(config, prefix, knownSectionKeys(config), applied)
+}
+
+// applyEnvKeys replaces the values of the given keys in config with the environment variables.
+// the keys in applied are skipped, and the keys checked are added into it.
+func applyEnvKeys(config *viper.Viper, prefix string, keys []string, applied map[string]bool) error {
+	overrides := make(map[string]interface{})
+
+	for _, key := range keys {
+		key = strings.ToLower(key)
+		if applied[key] {
+			continue
+		}
+		applied[key] = true
+
+		value, ok := os.LookupEnv(envName(prefix, key))
+		if !ok {
+			continue
+		}
+
+		typedValue, err := convertEnvValue(value, config.Get(key))
+		if err != nil {
+			return fmt.Errorf("the value of environment variable [%s] is invalid: %v", envName(prefix, key), err)
+		}
+		setNestedValue(overrides, key, typedValue)
+	}
+
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	return config.MergeConfigMap(overrides)
+}
+
+// knownSectionKeys returns the known keys of the sections referred by the loggers in config,
+// whether they are present in config or not.
+func knownSectionKeys(config *viper.Viper) []string {
+	var keys []string
+	addKeys := func(section string, known []string) {
+		for _, k := range known {
+			keys = append(keys, section+"."+k)
+		}
+	}
+
+	for _, loggerName := range append([]string{""}, definedLoggerNames(config)...) {
+		addKeys(loggerSectionName(loggerName, "options"), optionKeys)
+
+		for _, appender := range cast.ToStringSlice(config.Get(loggerSectionName(loggerName, "appenders"))) {
+			if appender = strings.TrimSpace(appender); appender == "" {
+				continue
+			}
+			addKeys(appender, appenderKeys)
+
+			target := strings.TrimSpace(config.GetString(appender + ".target"))
+			if target != "" && !strings.EqualFold(target, TargetStdout) && !strings.EqualFold(target, TargetStderr) {
+				addKeys(target, lumberjackKeys)
+			}
+			if encoderConfig := strings.TrimSpace(config.GetString(appender + ".encoderConfig")); encoderConfig != "" {
+				addKeys(encoderConfig, encoderConfigKeys)
+			}
+		}
+	}
+
+	return keys
+}
+
+// setNestedValue puts the value into nested maps according to the dotted key.
+func setNestedValue(settings map[string]interface{}, key string, value interface{}) {
+	section := settings
+	path := strings.Split(key, ".")
+
+	for _, p := range path[:len(path)-1] {
+		next, ok := section[p].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			section[p] = next
+		}
+		section = next
+	}
+
+	section[path[len(path)-1]] = value
+}
+
+// convertEnvValue converts the value of environment variable to the same type as the value in config,
+// because viper refuses to merge values in different types.
+func convertEnvValue(value string, configValue interface{}) (interface{}, error) {
+	switch configValue.(type) {
+	case bool:
+		return cast.ToBoolE(value)
+	case int:
+		return cast.ToIntE(value)
+	case int64:
+		return cast.ToInt64E(value)
+	case float64:
+		return cast.ToFloat64E(value)
+	case []interface{}:
+		items := make([]interface{}, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	default:
+		return value, nil
+	}
+}
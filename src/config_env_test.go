@@ -0,0 +1,62 @@
+package cfzap
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "CFZAP_APPENDER_FILE_LOGLEVEL", envName("CFZAP", "appender-file.logLevel"))
+	assert.Equal(t, "APP_LUMBERJACK2_FILENAME", envName("app", "lumberjack2.filename"))
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	env := map[string]string{
+		"TEST_CFZAP_APPENDER_FILE_LOGLEVEL": "Warn",
+		"TEST_CFZAP_LUMBERJACK2_MAXSIZE":    "5",
+		"TEST_CFZAP_APPENDERS":              "appender-stdout, ",
+		// the key is not a known key of appender section, so it's not used.
+		"TEST_CFZAP_APPENDER_FILE_UNKNOWN": "value",
+		// the known key of encoderConfig section is used even if it's not present in config file.
+		"TEST_CFZAP_ENCODERCONFIG_ENCODEDURATION": "ms",
+	}
+	for k, v := range env {
+		assert.Nil(t, os.Setenv(k, v))
+		defer os.Unsetenv(k)
+	}
+
+	option := NewConfigOption(
+		WithFileName("appender_config_ok"),
+		WithFileExt("yaml"),
+		WithFilePaths(testFilePath),
+		WithEnvPrefix("TEST_CFZAP"))
+	config, _, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read config file with environment variables")
+
+	assert.Equal(t, "Warn", config.GetString("appender-file.logLevel"), "logLevel should be overridden")
+	assert.Equal(t, 5, config.Sub("lumberjack2").GetInt("maxSize"), "maxSize should be overridden")
+	assert.Equal(t, 20, config.Sub("lumberjack2").GetInt("maxBackups"), "maxBackups should not be changed")
+	assert.Equal(t, []interface{}{"appender-stdout"}, config.Get("appenders"), "appenders should be overridden")
+	assert.False(t, config.IsSet("appender-file.unknown"), "the unknown key should not be added")
+	assert.Equal(t, "ms", config.GetString("encoderConfig.encodeDuration"),
+		"the known key of the section referred should be added")
+
+	appenders, _, err := loadAppenders(config)
+	assert.Nil(t, err, "fail to load appenders")
+	assert.Equal(t, 1, len(appenders), "appender count should be 1")
+
+	// without prefix, the environment variables are not used.
+	config, _, err = readConfigFile(NewConfigOption(
+		WithFileName("appender_config_ok"),
+		WithFileExt("yaml"),
+		WithFilePaths(testFilePath)))
+	assert.Nil(t, err, "fail to read config file without environment variables")
+	assert.Equal(t, "Debug", config.GetString("appender-file.logLevel"), "logLevel should not be overridden")
+
+	// the value should be converted to the type in config file.
+	assert.Nil(t, os.Setenv("TEST_CFZAP_LUMBERJACK2_MAXSIZE", "five"))
+	_, _, err = readConfigFile(option)
+	assert.NotNil(t, err, "the value of maxSize should be invalid")
+}
@@ -0,0 +1,188 @@
+package cfzap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MissingKeyError is returned when a required key doesn't exist.
+type MissingKeyError struct {
+	// Key is the full dotted path of the key.
+	Key string
+}
+
+// Error implements error interface.
+func (e *MissingKeyError) Error() string {
+	return fmt.Sprintf("the key [%s] does not exist", e.Key)
+}
+
+// MissingSectionError is returned when a required section doesn't exist.
+type MissingSectionError struct {
+	// Key is the name of the section.
+	Key string
+	// Referrer is the full dotted path of the key whose value is the section name, such as 'appender-file.encoderConfig'.
+	// It's empty when the section is not referred by name, such as 'appenders'.
+	Referrer string
+}
+
+// Error implements error interface.
+func (e *MissingSectionError) Error() string {
+	if e.Referrer == "" {
+		return fmt.Sprintf("missing section [%s]", e.Key)
+	}
+
+	return fmt.Sprintf("the value of [%s] is [%s], but the entry was missing", e.Referrer, e.Key)
+}
+
+// UnknownTargetError is returned when the target of an appender is neither 'stdout', 'stderr' nor an existing section.
+type UnknownTargetError struct {
+	// Appender is the name of the appender.
+	Appender string
+	// Target is the value of the target.
+	Target string
+}
+
+// Error implements error interface.
+func (e *UnknownTargetError) Error() string {
+	return fmt.Sprintf("the value of [%s.target] is [%s], but the entry was missing", e.Appender, e.Target)
+}
+
+// UnknownKeyError is returned when a key is not known in strict mode.
+type UnknownKeyError struct {
+	// Key is the full dotted path of the key.
+	Key string
+	// Suggestion is the known key which Key is most likely a typo of, it's empty if no known key is close enough.
+	Suggestion string
+}
+
+// Error implements error interface.
+func (e *UnknownKeyError) Error() string {
+	if e.Suggestion == "" {
+		return fmt.Sprintf("unknown key [%s]", e.Key)
+	}
+
+	return fmt.Sprintf("unknown key [%s], did you mean [%s]?", e.Key, e.Suggestion)
+}
+
+// InvalidValueError is returned when the value of a key is invalid.
+type InvalidValueError struct {
+	// Key is the full dotted path of the key.
+	Key string
+	// Value is the invalid value.
+	Value interface{}
+	// Reason describes why the value is invalid.
+	Reason string
+}
+
+// Error implements error interface.
+func (e *InvalidValueError) Error() string {
+	return fmt.Sprintf("the value [%v] of [%s] is invalid: %s", e.Value, e.Key, e.Reason)
+}
+
+// AppenderError is returned when an appender fails to load.
+type AppenderError struct {
+	// Appender is the name of the appender.
+	Appender string
+	// Key is the full dotted path of the key causing the error, it's empty if the error is not caused by a key.
+	Key string
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error implements error interface.
+func (e *AppenderError) Error() string {
+	return fmt.Sprintf("fail to load appender [%s]: %v", e.Appender, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *AppenderError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors lists all the errors found in the config.
+// It is returned with a working logger when only some appenders failed to load,
+// and errors.As() and errors.Is() match any of the errors it lists.
+type ConfigErrors struct {
+	// Errors is sorted by the keys causing the errors.
+	Errors []error
+}
+
+// Error implements error interface.
+func (e *ConfigErrors) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	if len(messages) == 1 {
+		return "1 error in config: " + messages[0]
+	}
+
+	return fmt.Sprintf("%d errors in config: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// As finds the first error in the list that matches target, and if so, sets target to that error value.
+// It's used by errors.As().
+func (e *ConfigErrors) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Is reports whether any error in the list matches target. It's used by errors.Is().
+func (e *ConfigErrors) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newConfigErrors returns ConfigErrors object listing the errors sorted by their keys,
+// it returns nil if there's no error.
+func newConfigErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sorted := append([]error(nil), errs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(errorKey(sorted[i])) < strings.ToLower(errorKey(sorted[j]))
+	})
+
+	return &ConfigErrors{Errors: sorted}
+}
+
+// errorKey returns the full dotted path of the key causing the error, it's empty if the error is not caused by a key.
+func errorKey(err error) string {
+	switch e := err.(type) {
+	case *MissingKeyError:
+		return e.Key
+	case *MissingSectionError:
+		if e.Referrer != "" {
+			return e.Referrer
+		}
+		return e.Key
+	case *UnknownTargetError:
+		return e.Appender + ".target"
+	case *UnknownKeyError:
+		return e.Key
+	case *InvalidValueError:
+		return e.Key
+	case *AppenderError:
+		if e.Key != "" {
+			return e.Key
+		}
+		return e.Appender
+	default:
+		return ""
+	}
+}
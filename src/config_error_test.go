@@ -0,0 +1,35 @@
+package cfzap
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigErrors(t *testing.T) {
+	assert.Nil(t, newConfigErrors(nil), "no error should be returned for empty list.")
+
+	err := newConfigErrors([]error{
+		&UnknownKeyError{Key: "lumberjack2.maxsize2", Suggestion: "maxSize"},
+		&AppenderError{Appender: "appender-file", Key: "lumberjack2.filename", Err: os.ErrPermission},
+		&MissingKeyError{Key: "appender-stdout.target"},
+	})
+
+	var configErrors *ConfigErrors
+	assert.True(t, errors.As(err, &configErrors), "ConfigErrors should be returned.")
+	assert.Equal(t, "appender-stdout.target", errorKey(configErrors.Errors[0]), "the errors should be sorted by key.")
+	assert.Equal(t, "lumberjack2.filename", errorKey(configErrors.Errors[1]), "the errors should be sorted by key.")
+
+	var appenderErr *AppenderError
+	assert.True(t, errors.As(err, &appenderErr), "any listed error should be matched.")
+	assert.Equal(t, "appender-file", appenderErr.Appender)
+	assert.True(t, errors.Is(err, os.ErrPermission), "the cause of the listed error should be matched.")
+
+	var targetErr *UnknownTargetError
+	assert.False(t, errors.As(err, &targetErr), "the error not listed should not be matched.")
+
+	assert.Equal(t, "1 error in config: the key [a.target] does not exist",
+		newConfigErrors([]error{&MissingKeyError{Key: "a.target"}}).Error())
+}
@@ -0,0 +1,178 @@
+package cfzap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// builtinVariables returns the value of the built-in variables, they take precedence over environment variables.
+//   - hostname: the host name reported by the kernel.
+//   - pid: the process id.
+//   - exe: the file name of the executable without extension.
+var builtinVariables = map[string]func() (string, error){
+	"hostname": os.Hostname,
+	"pid": func() (string, error) {
+		return strconv.Itoa(os.Getpid()), nil
+	},
+	"exe": func() (string, error) {
+		exe, err := os.Executable()
+		if err != nil {
+			return "", err
+		}
+
+		name := filepath.Base(exe)
+		return strings.TrimSuffix(name, filepath.Ext(name)), nil
+	},
+}
+
+// expandVariables expands the variables in all string values of config, including the items of lists.
+// see expandString() for the syntax.
+// it returns error when any variable cannot be expanded.
+func expandVariables(config *viper.Viper) error {
+	overrides := make(map[string]interface{})
+
+	for _, key := range config.AllKeys() {
+		var value interface{}
+
+		switch v := config.Get(key).(type) {
+		case string:
+			if !strings.Contains(v, "$") {
+				continue
+			}
+
+			s, err := expandString(v)
+			if err != nil {
+				return fmt.Errorf("fail to expand the value of [%s]: %v", key, err)
+			}
+			value = s
+		case []interface{}:
+			items := make([]interface{}, len(v))
+			for i, item := range v {
+				items[i] = item
+				if s, ok := item.(string); ok && strings.Contains(s, "$") {
+					s, err := expandString(s)
+					if err != nil {
+						return fmt.Errorf("fail to expand the value of [%s]: %v", key, err)
+					}
+					items[i] = s
+				}
+			}
+			value = items
+		default:
+			continue
+		}
+
+		setNestedValue(overrides, key, value)
+	}
+
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	return config.MergeConfigMap(overrides)
+}
+
+// expandString expands the variables in s. the syntax is:
+//   - ${NAME}: the value of the built-in variable or environment variable NAME, it's an error if NAME is undefined.
+//   - ${NAME:-default}: same as above, but default is used if NAME is undefined or empty.
+//   - $${: the literal '${'.
+//
+// the '$' not followed by '{' is kept as is.
+func expandString(s string) (string, error) {
+	var b strings.Builder
+
+	for {
+		i := strings.Index(s, "$")
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		if strings.HasPrefix(s, "$${") {
+			b.WriteString("${")
+			s = s[3:]
+			continue
+		}
+		if !strings.HasPrefix(s, "${") {
+			b.WriteString("$")
+			s = s[1:]
+			continue
+		}
+
+		end := strings.Index(s, "}")
+		if end < 0 {
+			return "", fmt.Errorf("missing '}' in [%s]", s)
+		}
+
+		value, err := lookupVariable(s[2:end])
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(value)
+		s = s[end+1:]
+	}
+}
+
+// escapeVariables returns a copy of value with '${' in all strings escaped as '$${',
+// including the strings in maps and lists, so the expanded value is read as it is again.
+func escapeVariables(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return strings.ReplaceAll(v, "${", "$${")
+	case map[string]interface{}:
+		escaped := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			escaped[k] = escapeVariables(item)
+		}
+		return escaped
+	case []interface{}:
+		escaped := make([]interface{}, len(v))
+		for i, item := range v {
+			escaped[i] = escapeVariables(item)
+		}
+		return escaped
+	default:
+		return value
+	}
+}
+
+// lookupVariable returns the value of the variable expression inside '${' and '}'.
+func lookupVariable(expression string) (string, error) {
+	name := expression
+	defaultValue := ""
+	hasDefault := false
+
+	if i := strings.Index(expression, ":-"); i >= 0 {
+		name = expression[:i]
+		defaultValue = expression[i+2:]
+		hasDefault = true
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("empty variable name in [${%s}]", expression)
+	}
+
+	if builtin, ok := builtinVariables[name]; ok {
+		return builtin()
+	}
+
+	if value, ok := os.LookupEnv(name); ok && (value != "" || !hasDefault) {
+		return value, nil
+	}
+
+	if hasDefault {
+		return defaultValue, nil
+	}
+
+	return "", fmt.Errorf("undefined variable [%s]", name)
+}
@@ -0,0 +1,69 @@
+package cfzap
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandString(t *testing.T) {
+	assert.Nil(t, os.Setenv("TEST_CFZAP_VAR", "value"))
+	defer os.Unsetenv("TEST_CFZAP_VAR")
+	assert.Nil(t, os.Setenv("TEST_CFZAP_EMPTY", ""))
+	defer os.Unsetenv("TEST_CFZAP_EMPTY")
+
+	cases := map[string]string{
+		"no variable":                        "no variable",
+		"${TEST_CFZAP_VAR}":                  "value",
+		"a/${TEST_CFZAP_VAR}/b":              "a/value/b",
+		"${TEST_CFZAP_UNDEFINED:-dft}":       "dft",
+		"${TEST_CFZAP_EMPTY:-dft}":           "dft",
+		"${TEST_CFZAP_EMPTY}":                "",
+		"${TEST_CFZAP_VAR:-dft}":             "value",
+		"$${TEST_CFZAP_VAR}":                 "${TEST_CFZAP_VAR}",
+		"$5 and $":                           "$5 and $",
+		"${pid}":                             strconv.Itoa(os.Getpid()),
+		"${TEST_CFZAP_VAR}${TEST_CFZAP_VAR}": "valuevalue",
+	}
+	for s, expected := range cases {
+		actual, err := expandString(s)
+		assert.Nilf(t, err, "fail to expand %q", s)
+		assert.Equalf(t, expected, actual, "wrong value expanded from %q", s)
+	}
+
+	hostname, _ := os.Hostname()
+	actual, _ := expandString("${hostname}")
+	assert.Equal(t, hostname, actual, "wrong host name")
+
+	_, err := expandString("${TEST_CFZAP_UNDEFINED}")
+	assert.NotNil(t, err, "undefined variable without default should be failed")
+	assert.Equal(t, "undefined variable [TEST_CFZAP_UNDEFINED]", err.Error())
+
+	_, err = expandString("${TEST_CFZAP_VAR")
+	assert.NotNil(t, err, "missing '}' should be failed")
+}
+
+func TestExpandVariables(t *testing.T) {
+	option := NewConfigOption(
+		WithFileName("expand_config"),
+		WithFileExt("yaml"),
+		WithFilePaths(testFilePath))
+
+	_, _, err := readConfigFile(option)
+	assert.NotNil(t, err, "TEST_CFZAP_LEVEL is undefined")
+	assert.Equal(t, "fail to expand the value of [appender-file.loglevel]: undefined variable [TEST_CFZAP_LEVEL]", err.Error())
+
+	assert.Nil(t, os.Setenv("TEST_CFZAP_LEVEL", "Debug"))
+	defer os.Unsetenv("TEST_CFZAP_LEVEL")
+
+	config, _, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read config file with variables")
+	assert.Equal(t, []interface{}{"appender-file"}, config.Get("appenders"))
+	assert.Equal(t, "Debug", config.GetString("appender-file.logLevel"))
+	assert.Equal(t, "${not expanded}", config.GetString("encoderConfig.encodeTime"))
+
+	exe, _ := builtinVariables["exe"]()
+	assert.Equal(t, "logs/"+exe+"-"+strconv.Itoa(os.Getpid())+".log", config.Sub("lumberjack2").GetString("filename"))
+}
@@ -1,18 +1,113 @@
 package cfzap
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // readConfigFile reads configuration from specified config file.
-// It returns config object and nil when success, otherwise nil and error object.
-func readConfigFile(configOption *ConfigOption) (*viper.Viper, error) {
+// the config file can also be provided by io.Reader, byte slice or fs.FS.
+// the config files included by it are loaded, then the profile config file and the overlay config files
+// are merged over it in order.
+// In strict mode, the problems found by checking the config are returned as *ConfigErrors.
+// It returns config object, the description of the loaded config files and nil when success,
+// otherwise nil and error object.
+func readConfigFile(configOption *ConfigOption) (*viper.Viper, []ConfigSource, error) {
+	config, sources, problems, err := readConfig(configOption, configOption.Strict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := newConfigErrors(problems); err != nil {
+		return nil, nil, err
+	}
+
+	return config, sources, nil
+}
+
+// readConfig reads configuration as readConfigFile() does, the problems are checked only if check is true.
+// the problems include the unknown keys of zap.Config, and those found in the converted config.
+// It returns config object, the description of the loaded config files, the problems sorted by key and nil
+// when success, otherwise nil and error object.
+func readConfig(configOption *ConfigOption, check bool) (*viper.Viper, []ConfigSource, []error, error) {
+	var config *viper.Viper
+	var source ConfigSource
+	var err error
+
+	switch {
+	case configOption.Reader != nil:
+		config, source, err = readConfigData(configOption.Reader, configOption.FileExt)
+	case configOption.Data != nil:
+		config, source, err = readConfigData(bytes.NewReader(configOption.Data), configOption.FileExt)
+	case configOption.FS != nil:
+		config, source, err = readConfigFS(configOption.FS, configOption.FSPath, configOption.FileExt)
+	case strings.TrimSpace(configOption.FilePath) != "":
+		config, source, err = readConfigPath(strings.TrimSpace(configOption.FilePath), configOption.FileExt)
+	default:
+		config, source, err = searchConfigFile(configOption)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	config, sources, err := resolveIncludes(config, source, configOption.FS)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the overlays must be merged before applyEnvOverrides(), so the environment variables win.
+	config, sources, err = applyOverlays(config, sources, configOption)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if prefix := strings.TrimSpace(configOption.EnvPrefix); prefix != "" {
+		if err := applyEnvOverrides(config, prefix); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// must be called after applyEnvOverrides() because the environment variables may contain variables too.
+	if err := expandVariables(config); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// zap.Config is converted to the format of cfzap, so it's loaded and validated in the same way.
+	var problems []error
+	if isZapConfig(config) {
+		if check {
+			problems = checkZapConfig(config)
+		}
+		if config, err = convertZapConfig(config); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if check {
+		problems = append(problems, checkConfig(config)...)
+		sortProblems(problems)
+	}
+
+	return config, sources, problems, nil
+}
+
+// searchConfigFile searches the config file in the paths specified by configOption, then reads it.
+// It returns config object, the description of the loaded config file and nil when success,
+// otherwise nil and error object.
+func searchConfigFile(configOption *ConfigOption) (*viper.Viper, ConfigSource, error) {
 	configType, typeErr := checkConfigType(configOption.FileExt)
 	if typeErr != nil {
-		return nil, typeErr
+		return nil, ConfigSource{}, typeErr
 	}
 
 	config := viper.New()
@@ -33,11 +128,134 @@ func readConfigFile(configOption *ConfigOption) (*viper.Viper, error) {
 		config.AddConfigPath(".")
 	}
 
+	// Viper is used to find the file, which may have any supported extension.
 	if err := config.ReadInConfig(); err != nil {
-		return nil, err
+		return nil, ConfigSource{}, err
+	}
+
+	// the file is read again, so the content parsed is the one described by the source.
+	return readConfigPath(config.ConfigFileUsed(), configType)
+}
+
+// readConfigPath reads configuration from the file specified by filePath, no other file is searched.
+// the config type is decided by the file extension when fileExt is empty.
+// It returns config object, the description of the loaded config file and nil when success,
+// otherwise nil and error object.
+func readConfigPath(filePath string, fileExt string) (*viper.Viper, ConfigSource, error) {
+	if strings.TrimSpace(fileExt) == "" {
+		fileExt = strings.TrimPrefix(filepath.Ext(filePath), ".")
+	}
+
+	configType, err := checkConfigType(fileExt)
+	if err != nil {
+		return nil, ConfigSource{}, err
+	}
+	if configType == "" {
+		return nil, ConfigSource{}, fmt.Errorf("cannot decide config type of [%s]", filePath)
+	}
+
+	// the content is read only once, so the hash and modification time describe the content parsed,
+	// even if the file is changed at the same time.
+	path, modTime, data, err := readFile(filePath)
+	if err != nil {
+		return nil, ConfigSource{}, err
 	}
 
-	return config, nil
+	config, source, err := readConfigData(bytes.NewReader(data), configType)
+	if err != nil {
+		return nil, ConfigSource{}, err
+	}
+
+	// keep the file reported by ConfigFileUsed, though the content is not read by Viper.
+	config.SetConfigFile(filePath)
+	source.Path = path
+	source.ModTime = modTime
+
+	return config, source, nil
+}
+
+// readFile reads the file in file system.
+// It returns the absolute path, the modification time and the content of the file, and error object.
+func readFile(filePath string) (string, time.Time, []byte, error) {
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+
+	return path, info.ModTime(), data, nil
+}
+
+// readConfigData reads configuration from reader, the config type is required.
+// It returns config object, the description of the content and nil when success, otherwise nil and error object.
+func readConfigData(reader io.Reader, fileExt string) (*viper.Viper, ConfigSource, error) {
+	configType, err := checkConfigType(fileExt)
+	if err != nil {
+		return nil, ConfigSource{}, err
+	}
+	if configType == "" {
+		return nil, ConfigSource{}, errors.New("config type is required when reading config from io.Reader or byte slice")
+	}
+
+	// keep the content to get its hash.
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ConfigSource{}, err
+	}
+
+	config := viper.New()
+	config.SetConfigType(configType)
+
+	if err := config.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, ConfigSource{}, err
+	}
+
+	return config, newConfigSource("", configType, time.Time{}, data), nil
+}
+
+// readConfigFS reads configuration from the file in fsys.
+// the config type is decided by the file extension when fileExt is empty.
+// It returns config object, the description of the loaded config file and nil when success,
+// otherwise nil and error object.
+func readConfigFS(fsys fs.FS, filePath string, fileExt string) (*viper.Viper, ConfigSource, error) {
+	info, err := fs.Stat(fsys, filePath)
+	if err != nil {
+		return nil, ConfigSource{}, err
+	}
+
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return nil, ConfigSource{}, err
+	}
+
+	if strings.TrimSpace(fileExt) == "" {
+		fileExt = strings.TrimPrefix(path.Ext(filePath), ".")
+	}
+
+	config, source, err := readConfigData(bytes.NewReader(data), fileExt)
+	if err != nil {
+		return nil, ConfigSource{}, err
+	}
+
+	source.Path = filePath
+	source.ModTime = info.ModTime()
+
+	return config, source, nil
 }
 
 // checkConfigType checks provided file extension.
@@ -1,7 +1,12 @@
 package cfzap
 
 import (
+	"bytes"
+	"embed"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,22 +16,90 @@ func TestReadConfigFile(t *testing.T) {
 
 	// test read config file with wrong type.
 	// using cfzap.abc as the config file.
-	_, err = readConfigFile(NewConfigOption(WithFileExt("abc")))
+	_, _, err = readConfigFile(NewConfigOption(WithFileExt("abc")))
 	assert.NotNil(t, err)
 	assert.Equal(t, "unsupported Config type [abc]", err.Error(), "not expected error.")
 
 	// test read config file with default name and default type.
 	// using cfzap.json as the config file.
-	_, err = readConfigFile(NewConfigOption(WithFileName(""), WithFileExt(""), WithFilePaths(testFilePath)))
+	_, _, err = readConfigFile(NewConfigOption(WithFileName(""), WithFileExt(""), WithFilePaths(testFilePath)))
 	assert.Nil(t, err, "fail to read config file in default type.")
 
 	// test read config file with default name and json type.
 	// using cfzap.json as the config file.
-	_, err = readConfigFile(NewConfigOption(WithFileName(""), WithFileExt("json"), WithFilePaths(testFilePath)))
+	_, _, err = readConfigFile(NewConfigOption(WithFileName(""), WithFileExt("json"), WithFilePaths(testFilePath)))
 	assert.Nil(t, err, "fail to read config file in json type.")
 
 	// test read config file with given name and yaml type.
 	// using configfile.yaml as the config file.
-	_, err = readConfigFile(NewConfigOption(WithFileName("configfile"), WithFileExt("yaml"), WithFilePaths(testFilePath)))
+	_, _, err = readConfigFile(NewConfigOption(WithFileName("configfile"), WithFileExt("yaml"), WithFilePaths(testFilePath)))
 	assert.Nil(t, err, "fail to read config file in yaml type.")
 }
+
+//go:embed test_config_file/cfzap.json test_config_file/configfile.yaml
+var testFS embed.FS
+
+func TestReadConfigFromSource(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testFilePath, "appender_config_ok.yaml"))
+	assert.Nil(t, err, "fail to read test file.")
+
+	// test read config from byte slice.
+	config, _, err := readConfigFile(NewConfigOption(WithData(data, "yaml")))
+	assert.Nil(t, err, "fail to read config from byte slice.")
+	assert.Equal(t, "Debug", config.GetString("appender-file.logLevel"), "wrong value from byte slice.")
+
+	// test read config from io.Reader.
+	config, _, err = readConfigFile(NewConfigOption(WithReader(bytes.NewReader(data), "yaml")))
+	assert.Nil(t, err, "fail to read config from io.Reader.")
+	assert.Equal(t, "Debug", config.GetString("appender-file.logLevel"), "wrong value from io.Reader.")
+
+	// config type is required for io.Reader.
+	_, _, err = readConfigFile(NewConfigOption(WithReader(bytes.NewReader(data), "")))
+	assert.NotNil(t, err, "config type should be required for io.Reader.")
+
+	// test read config from embed.FS, config type is decided by the extension.
+	config, _, err = readConfigFile(NewConfigOption(WithFS(testFS, "test_config_file/cfzap.json")))
+	assert.Nil(t, err, "fail to read json config from embed.FS.")
+	assert.Equal(t, "Info", config.GetString("appender-stdout.logLevel"), "wrong value from embed.FS.")
+
+	_, _, err = readConfigFile(NewConfigOption(WithFS(testFS, "test_config_file/configfile.yaml")))
+	assert.Nil(t, err, "fail to read yaml config from embed.FS.")
+
+	_, _, err = readConfigFile(NewConfigOption(WithFS(testFS, "test_config_file/no_file.yaml")))
+	assert.NotNil(t, err, "should be failed because there's no such file in embed.FS.")
+
+	// test read config from fs.FS which is not comparable.
+	mapFS := fstest.MapFS{"log.yaml": &fstest.MapFile{Data: data}}
+	option := NewConfigOption(WithFS(mapFS, "log.yaml"))
+	_, _, err = readConfigFile(option)
+	assert.Nil(t, err, "fail to read config from fstest.MapFS.")
+	assert.True(t, option.equal(NewConfigOption(WithFS(mapFS, "log.yaml"))), "same fs.FS should be equal.")
+}
+
+func TestReadConfigFromPath(t *testing.T) {
+	// both cfzap.json and cfzap.yaml exist, only the given one should be loaded.
+	config, _, err := readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "cfzap.yaml"))))
+	assert.Nil(t, err, "fail to read config file from path.")
+	assert.Equal(t, filepath.Join(testFilePath, "cfzap.yaml"), config.ConfigFileUsed(), "wrong config file loaded.")
+
+	// the file name and paths are ignored.
+	config, _, err = readConfigFile(NewConfigOption(
+		WithFileName("cfzap"),
+		WithFileExt(""),
+		WithFilePaths(testFilePath),
+		WithFilePath(filepath.Join(testFilePath, "configfile.yaml"))))
+	assert.Nil(t, err, "fail to read config file from path.")
+	assert.Equal(t, filepath.Join(testFilePath, "configfile.yaml"), config.ConfigFileUsed(), "wrong config file loaded.")
+
+	// never load another file when the given one doesn't exist.
+	_, _, err = readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "cfzap.yml"))))
+	assert.NotNil(t, err, "should be failed because there's no such file.")
+
+	_, _, err = readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "cfzap"))))
+	assert.NotNil(t, err, "should be failed because the config type is unknown.")
+	assert.Equal(t, "cannot decide config type of ["+filepath.Join(testFilePath, "cfzap")+"]", err.Error())
+
+	_, _, err = readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "cfzap.abc"))))
+	assert.NotNil(t, err, "should be failed because the config type is unsupported.")
+	assert.Equal(t, "unsupported Config type [abc]", err.Error(), "not expected error.")
+}
@@ -0,0 +1,167 @@
+package cfzap
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// includeKey is the top level key listing the config files included by a config file.
+const includeKey = "include"
+
+// includeLoader loads a config file and the config files it includes into one key space.
+type includeLoader struct {
+	// the file system of the included files, they are in the file system if it's nil.
+	fsys fs.FS
+	// the top level settings of all loaded files.
+	settings map[string]interface{}
+	// the include chain of the file defining the top level key.
+	owners map[string][]string
+	// the files loaded already, a file included more than once is loaded only once.
+	loaded map[string]bool
+	// the description of all loaded files.
+	sources []ConfigSource
+}
+
+// resolveIncludes loads the config files included by config recursively, and puts their keys into config.
+// source describes the config, the relative path of the included file is relative to it.
+// It returns the config object including all keys, the description of all loaded config files and nil
+// when success, otherwise nil and error object.
+// it's an error if the files include each other, or the same top level key is defined by more than one file.
+func resolveIncludes(config *viper.Viper, source ConfigSource, fsys fs.FS) (*viper.Viper, []ConfigSource, error) {
+	if !config.IsSet(includeKey) {
+		return config, []ConfigSource{source}, nil
+	}
+
+	loader := &includeLoader{
+		fsys:     fsys,
+		settings: make(map[string]interface{}),
+		owners:   make(map[string][]string),
+		loaded:   make(map[string]bool),
+	}
+	if err := loader.load(config, source, nil); err != nil {
+		return nil, nil, err
+	}
+
+	merged := viper.New()
+	if err := merged.MergeConfigMap(loader.settings); err != nil {
+		return nil, nil, err
+	}
+
+	return merged, loader.sources, nil
+}
+
+// load puts the keys of config into the key space, then loads the files it includes.
+// chain is the include chain of the files including config.
+func (loader *includeLoader) load(config *viper.Viper, source ConfigSource, chain []string) error {
+	chain = append(append([]string(nil), chain...), sourceName(source))
+	if source.Path != "" {
+		loader.loaded[source.Path] = true
+	}
+	loader.sources = append(loader.sources, source)
+
+	settings := config.AllSettings()
+	for key, value := range settings {
+		if key == includeKey {
+			continue
+		}
+		if owner, ok := loader.owners[key]; ok {
+			return fmt.Errorf("key [%s] is defined in both [%s] and [%s]",
+				key, strings.Join(owner, " -> "), strings.Join(chain, " -> "))
+		}
+
+		loader.owners[key] = chain
+		loader.settings[key] = value
+	}
+
+	includes, err := includeList(settings[includeKey])
+	if err != nil {
+		return fmt.Errorf("the value of [%s] in [%s] is invalid: %v", includeKey, sourceName(source), err)
+	}
+
+	for _, include := range includes {
+		filePath, err := loader.resolve(source.Path, include)
+		if err != nil {
+			return err
+		}
+
+		if StringInArray(filePath, chain) {
+			return fmt.Errorf("config files include each other: [%s -> %s]", strings.Join(chain, " -> "), filePath)
+		}
+		if loader.loaded[filePath] {
+			continue
+		}
+
+		var includedConfig *viper.Viper
+		var includedSource ConfigSource
+		if loader.fsys != nil {
+			includedConfig, includedSource, err = readConfigFS(loader.fsys, filePath, "")
+		} else {
+			includedConfig, includedSource, err = readConfigPath(filePath, "")
+		}
+		if err != nil {
+			return fmt.Errorf("fail to include config [%s -> %s]: %v", strings.Join(chain, " -> "), filePath, err)
+		}
+
+		if err := loader.load(includedConfig, includedSource, chain); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the path of the included file, the relative path is relative to the including file.
+// the path is absolute for the file in the file system, so it's same as the path in ConfigSource.
+func (loader *includeLoader) resolve(includingPath string, include string) (string, error) {
+	if loader.fsys != nil {
+		if includingPath == "" {
+			return path.Clean(include), nil
+		}
+		return path.Join(path.Dir(includingPath), include), nil
+	}
+
+	if !filepath.IsAbs(include) && includingPath != "" {
+		include = filepath.Join(filepath.Dir(includingPath), include)
+	}
+
+	return filepath.Abs(include)
+}
+
+// includeList returns the included files, the value can be a single file or a list.
+func includeList(value interface{}) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if s, ok := value.(string); ok {
+		value = []string{s}
+	}
+
+	list, err := cast.ToStringSliceE(value)
+	if err != nil {
+		return nil, err
+	}
+
+	includes := make([]string, 0, len(list))
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			includes = append(includes, s)
+		}
+	}
+
+	return includes, nil
+}
+
+// sourceName returns the name of the config source used in messages.
+func sourceName(source ConfigSource) string {
+	if source.Path == "" {
+		return "config data"
+	}
+
+	return source.Path
+}
@@ -0,0 +1,42 @@
+package cfzap
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadConfigWithInclude(t *testing.T) {
+	config, sources, err := readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "include_config.yaml"))))
+	assert.Nil(t, err, "fail to read config file with include.")
+	assert.Equal(t, 3, len(sources), "the file included twice should be loaded once.")
+	assert.Equal(t, "encoder.yaml", filepath.Base(sources[1].Path), "wrong included file loaded.")
+	assert.Equal(t, "json", sources[2].Format, "the format of included file should be decided by its extension.")
+
+	assert.Equal(t, "MSG", config.GetString("encoderConfig.messageKey"), "the included key should be loaded.")
+	assert.Equal(t, 1, config.GetInt("lumberjack2.maxSize"), "the included key should be loaded.")
+	assert.Equal(t, "stdout", config.GetString("appender-stdout.target"), "the key of including file should be kept.")
+	assert.False(t, config.IsSet(includeKey), "the include key should be removed.")
+
+	appenders, failed, err := loadAppenders(config)
+	assert.Nil(t, err, "fail to load appenders from the included sections.")
+	assert.Equal(t, 1, len(appenders), "wrong appenders loaded.")
+	assert.Equal(t, 0, len(failed), "no appender should fail.")
+}
+
+func TestReadConfigWithIncludeError(t *testing.T) {
+	_, _, err := readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "include", "cycle_a.yaml"))))
+	assert.NotNil(t, err, "the include cycle should be reported.")
+	assert.True(t, strings.Contains(err.Error(), "include each other"), "not expected error: %v", err)
+	assert.Equal(t, 3, strings.Count(err.Error(), "cycle_"), "the include chain should be reported: %v", err)
+
+	_, _, err = readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "include", "conflict.yaml"))))
+	assert.NotNil(t, err, "the conflict key should be reported.")
+	assert.True(t, strings.Contains(err.Error(), "key [encoderconfig] is defined in both"), "not expected error: %v", err)
+	assert.True(t, strings.Contains(err.Error(), "conflict.yaml -> "), "the include chain should be reported: %v", err)
+
+	_, _, err = readConfigFile(NewConfigOption(WithData([]byte("include: missing.yaml"), "yaml")))
+	assert.NotNil(t, err, "the missing included file should be reported.")
+}
@@ -0,0 +1,214 @@
+package cfzap
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProfileEnvName is the environment variable providing the profile when ConfigOption doesn't set one.
+const ProfileEnvName = "CFZAP_PROFILE"
+
+const (
+	// listAddPrefix marks the item added to the inherited list.
+	listAddPrefix = "+"
+	// listRemovePrefix marks the item removed from the inherited list.
+	listRemovePrefix = "-"
+)
+
+// applyOverlays merges the profile config file and the overlay config files over the base config in order.
+// sources describes the config files loaded for the base config, the first one is the base config file
+// and the profile config file is located next to it.
+// the profile from the environment variable 'CFZAP_PROFILE' is used when configOption has none,
+// and its profile config file is skipped if it doesn't exist.
+// It returns the merged config object, the description of all loaded config files and nil when success,
+// otherwise nil and error object.
+func applyOverlays(config *viper.Viper, sources []ConfigSource, configOption *ConfigOption) (*viper.Viper, []ConfigSource, error) {
+	base := sources[0]
+
+	overlays := make([]string, 0, len(configOption.Overlays)+1)
+	formats := make([]string, 0, len(configOption.Overlays)+1)
+
+	profile, optionalProfile := strings.TrimSpace(configOption.Profile), false
+	if profile == "" {
+		profile, optionalProfile = strings.TrimSpace(os.Getenv(ProfileEnvName)), true
+	}
+
+	// the profile is ignored when the base config has no path.
+	hasProfile := profile != "" && base.Path != ""
+	if hasProfile {
+		overlays = append(overlays, profileConfigPath(base.Path, profile, configOption.FS == nil))
+		formats = append(formats, base.Format)
+	}
+	for _, overlay := range configOption.Overlays {
+		if s := strings.TrimSpace(overlay); s != "" {
+			overlays = append(overlays, s)
+			formats = append(formats, "")
+		}
+	}
+
+	if len(overlays) == 0 {
+		return config, sources, nil
+	}
+
+	settings := config.AllSettings()
+	for i, overlay := range overlays {
+		var overlayConfig *viper.Viper
+		var source ConfigSource
+		var err error
+
+		if configOption.FS != nil {
+			overlayConfig, source, err = readConfigFS(configOption.FS, overlay, formats[i])
+		} else {
+			overlayConfig, source, err = readConfigPath(overlay, formats[i])
+		}
+		if err != nil && i == 0 && hasProfile && optionalProfile && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("fail to read overlay config [%s]: %v", overlay, err)
+		}
+
+		// the overlay config file can include other config files too.
+		overlayConfig, overlaySources, err := resolveIncludes(overlayConfig, source, configOption.FS)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		settings = mergeSettings(settings, overlayConfig.AllSettings())
+		sources = append(sources, overlaySources...)
+	}
+
+	merged := viper.New()
+	if err := merged.MergeConfigMap(settings); err != nil {
+		return nil, nil, err
+	}
+
+	return merged, sources, nil
+}
+
+// profileConfigPath returns the path of the profile config file next to the base config file.
+// for example, the profile config file of 'cfzap.yaml' with profile 'prod' is 'cfzap.prod.yaml'.
+// the base path is a file system path when inFileSystem is true, otherwise it's a path in fs.FS.
+func profileConfigPath(basePath string, profile string, inFileSystem bool) string {
+	ext := path.Ext(basePath)
+	if inFileSystem {
+		ext = filepath.Ext(basePath)
+	}
+
+	return strings.TrimSuffix(basePath, ext) + "." + profile + ext
+}
+
+// mergeSettings deep merges overlay into base and returns the result, neither of them is changed.
+// the maps are merged key by key, the lists are merged by mergeList(), other values are replaced.
+func mergeSettings(base map[string]interface{}, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range overlay {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if baseValue, ok := merged[k].(map[string]interface{}); ok {
+				merged[k] = mergeSettings(baseValue, value)
+			} else {
+				merged[k] = value
+			}
+		case []interface{}, []string:
+			merged[k] = mergeList(merged[k], value)
+		default:
+			merged[k] = value
+		}
+	}
+
+	return merged
+}
+
+// mergeList merges the overlay list into the base list.
+// if any item of overlay is a string prefixed by '+' or '-', overlay patches base:
+// the item prefixed by '-' is removed from base, the other items are appended if they are not in base.
+// otherwise overlay replaces base.
+func mergeList(base interface{}, overlay interface{}) []interface{} {
+	overlayList := toList(overlay)
+	if !isListPatch(overlayList) {
+		return overlayList
+	}
+
+	merged := toList(base)
+	for _, item := range overlayList {
+		s, ok := item.(string)
+		if !ok {
+			merged = append(merged, item)
+			continue
+		}
+
+		if strings.HasPrefix(s, listRemovePrefix) {
+			merged = removeListItem(merged, strings.TrimPrefix(s, listRemovePrefix))
+			continue
+		}
+
+		s = strings.TrimPrefix(s, listAddPrefix)
+		if !listContains(merged, s) {
+			merged = append(merged, s)
+		}
+	}
+
+	return merged
+}
+
+// isListPatch checks to see if any item of the list is a string prefixed by '+' or '-'.
+func isListPatch(list []interface{}) bool {
+	for _, item := range list {
+		if s, ok := item.(string); ok && (strings.HasPrefix(s, listAddPrefix) || strings.HasPrefix(s, listRemovePrefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toList returns a copy of the list value, it returns nil if the value is not a list.
+func toList(value interface{}) []interface{} {
+	switch list := value.(type) {
+	case []interface{}:
+		return append([]interface{}(nil), list...)
+	case []string:
+		result := make([]interface{}, 0, len(list))
+		for _, s := range list {
+			result = append(result, s)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// removeListItem removes all items equal to value from the list.
+func removeListItem(list []interface{}, value string) []interface{} {
+	result := list[:0]
+	for _, item := range list {
+		if s, ok := item.(string); !ok || s != value {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// listContains checks to see if the list contains the value.
+func listContains(list []interface{}, value string) bool {
+	for _, item := range list {
+		if s, ok := item.(string); ok && s == value {
+			return true
+		}
+	}
+
+	return false
+}
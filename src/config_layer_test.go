@@ -0,0 +1,75 @@
+package cfzap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadConfigWithProfile(t *testing.T) {
+	option := NewConfigOption(WithFileName("layer_config"), WithFileExt("yaml"), WithFilePaths(testFilePath),
+		WithProfile("prod"))
+	config, sources, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read config file with profile.")
+	assert.Equal(t, 2, len(sources), "the base and profile config files should be loaded.")
+	assert.Equal(t, "layer_config.prod.yaml", filepath.Base(sources[1].Path), "wrong profile config file loaded.")
+
+	assert.Equal(t, []string{"appender-stderr"}, config.GetStringSlice("appenders"),
+		"appender-stdout should be removed from the inherited list.")
+	assert.Equal(t, "Error", config.GetString("appender-stderr.logLevel"), "the value should be overridden.")
+	assert.Equal(t, "stderr", config.GetString("appender-stderr.target"), "the value should be inherited.")
+	assert.Equal(t, "message", config.GetString("encoderConfig.messageKey"), "the value should be overridden.")
+	assert.Equal(t, "LEVEL", config.GetString("encoderConfig.levelKey"), "the value should be inherited.")
+
+	_, _, err = readConfigFile(cloneConfigOption(option, WithProfile("dev")))
+	assert.NotNil(t, err, "the missing profile config file should be reported.")
+}
+
+func TestReadConfigWithProfileFromEnv(t *testing.T) {
+	assert.Nil(t, os.Setenv(ProfileEnvName, "prod"))
+	defer func() { _ = os.Unsetenv(ProfileEnvName) }()
+
+	option := &ConfigOption{FilePath: filepath.Join(testFilePath, "layer_config.yaml")}
+	_, sources, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read config file with profile from environment variable.")
+	assert.Equal(t, 2, len(sources), "the profile config file should be loaded for struct literal too.")
+
+	// the missing profile config file is skipped.
+	assert.Nil(t, os.Setenv(ProfileEnvName, "dev"))
+	_, sources, err = readConfigFile(option)
+	assert.Nil(t, err, "the missing profile config file from environment variable should be skipped.")
+	assert.Equal(t, 1, len(sources), "only the base config file should be loaded.")
+
+	_, _, err = readConfigFile(cloneConfigOption(option, WithProfile("dev")))
+	assert.NotNil(t, err, "the missing profile config file should be reported for explicit profile.")
+}
+
+func TestReadConfigWithOverlays(t *testing.T) {
+	option := NewConfigOption(WithFilePath(filepath.Join(testFilePath, "layer_config.yaml")), WithProfile("prod"),
+		WithOverlays(filepath.Join(testFilePath, "layer_overlay.json")))
+	config, sources, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read config file with overlays.")
+	assert.Equal(t, 3, len(sources), "all config files should be loaded.")
+	assert.Equal(t, "json", sources[2].Format, "the format of overlay should be decided by its extension.")
+
+	// the list without '+' or '-' replaces the inherited one.
+	assert.Equal(t, []string{"appender-stdout"}, config.GetStringSlice("appenders"), "the list should be replaced.")
+	assert.Equal(t, "Info", config.GetString("appender-stdout.logLevel"), "the value should be overridden.")
+	assert.Equal(t, "Error", config.GetString("appender-stderr.logLevel"), "the profile value should be inherited.")
+
+	_, _, err = readConfigFile(cloneConfigOption(option, WithOverlays("missing.yaml")))
+	assert.NotNil(t, err, "the missing overlay config file should be reported.")
+}
+
+func TestMergeList(t *testing.T) {
+	base := []interface{}{"a", "b"}
+
+	assert.Equal(t, []interface{}{"c"}, mergeList(base, []interface{}{"c"}), "the list should be replaced.")
+	assert.Equal(t, []interface{}{"b", "c"}, mergeList(base, []interface{}{"-a", "+c"}), "the list should be patched.")
+	assert.Equal(t, []interface{}{"a", "b", "c"}, mergeList(base, []interface{}{"+b", "c", "+c"}),
+		"the existing items should not be added again.")
+	assert.Equal(t, []interface{}{"a"}, mergeList(nil, []interface{}{"+a", "-b"}), "the base can be missing.")
+	assert.Equal(t, []interface{}{"a", "b"}, base, "the base should not be changed.")
+}
@@ -1,5 +1,12 @@
 package cfzap
 
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"reflect"
+)
+
 // ConfigOption defines the information needed to create a logger from a config file.
 type ConfigOption struct {
 	// CreateNew indicates if a new logger should be created.
@@ -12,10 +19,69 @@ type ConfigOption struct {
 	// If this field is set to empty string (default), the extensions be searched one by one according to above list.
 	// Even you set a value other than 'json', such as 'yaml', it'll load 'json' file if the 'json' file exists.
 	// It's better giving a non empty value to this field if 'FileName' has extension.
+	// Use FilePath instead if the exact file should be loaded.
 	FileExt string
 	// FilePaths is the path list that the config file may be located.
 	// If this field is nil or empty, current executable path, current path will be used.
 	FilePaths []string
+	// Watch indicates if the config file should be watched after the logger was created.
+	// When the file changes, the appenders are reloaded and swapped into the logger already returned.
+	// If the new file is invalid, the previous configuration is kept and the error is reported
+	// by ReloadErrorHandler.
+	Watch bool
+	// ReloadErrorHandler is called with the error when the watched config file fails to reload, default is nil.
+	// It's also called with *ConfigErrors when some appenders fail to load, and the others are used.
+	// It's called without holding any lock, so it can create loggers. The error is logged by the default logger too.
+	// It doesn't identify the cached logger, the one given last time the logger is returned is used.
+	// It's not called after the logger is removed, closed, or rebuilt.
+	ReloadErrorHandler func(error)
+	// EnvPrefix enables overriding the values in config file by environment variables, default is empty string.
+	// If this field is empty, the environment variables are not used.
+	// The environment variable name of a key is the key in upper case, with '.' and '-' replaced by '_',
+	// and prefixed by EnvPrefix and '_'. For example, with EnvPrefix 'CFZAP',
+	// 'appender-file.logLevel' is overridden by 'CFZAP_APPENDER_FILE_LOGLEVEL'.
+	// The keys present in config file, and the known keys of the options, appender, lumberjack and encoderConfig
+	// sections used by the loggers can be overridden, such as 'CFZAP_LUMBERJACK_COMPRESS' even if 'compress' is
+	// not in the file. Since '.' and '-' are both replaced by '_', the keys such as 'a-b.c' and 'a.b-c' share the same
+	// variable. The value of a list, such as 'appenders', is separated by ','.
+	EnvPrefix string
+	// Reader provides the config content instead of the config file, default is nil.
+	// FileExt is required to tell the config type. The reader is read only once,
+	// so the logger cannot be created again, nor watched.
+	Reader io.Reader
+	// Data provides the config content instead of the config file, default is nil.
+	// FileExt is required to tell the config type. The logger cannot be watched.
+	Data []byte
+	// FS provides the config file instead of the file system, such as embed.FS, default is nil.
+	// The file is specified by FSPath, and the config type is decided by its extension if FileExt is empty.
+	// The logger cannot be watched.
+	FS fs.FS
+	// FSPath is the path of the config file in FS.
+	FSPath string
+	// FilePath is the full path of the config file, such as '/etc/app/logging.yaml', default is empty string.
+	// If this field is set, FileName and FilePaths are ignored, and only this file is loaded.
+	// The config type is decided by the file extension if FileExt is empty.
+	FilePath string
+	// Profile is the name of the environment, such as 'prod' or 'dev', default is empty string.
+	// If this field is set, the profile config file next to the config file, such as 'cfzap.prod.yaml' for
+	// 'cfzap.yaml', is merged over the config file. It's an error if the profile config file doesn't exist.
+	// If this field is empty, the environment variable 'CFZAP_PROFILE' is used when the config is read,
+	// and the profile config file is merged only if it exists.
+	// The profile is ignored when the config is read from io.Reader or byte slice.
+	Profile string
+	// Overlays is the ordered list of config files merged over the config file and the profile config file,
+	// default is nil. The files are in FS if FS is set, and the config type is decided by their extensions.
+	// The later file is deep merged over the earlier ones: the sections are merged key by key,
+	// and other values are replaced. A list, such as 'appenders', is replaced too, unless any of its items is
+	// prefixed by '+' or '-', then the items prefixed by '-' are removed from the inherited list,
+	// and other items are added to it, such as ['-appender-stdout', '+appender-stderr'].
+	Overlays []string
+	// Strict indicates if the config should be checked strictly, default is false.
+	// In strict mode, the unknown keys, the invalid enum values such as 'encodeLevel: capitol', the values
+	// which are not a number or a bool as expected, and the appenders failed to load are all reported
+	// with their dotted keys, and no logger is created. Otherwise they are ignored or the default values are used.
+	// The keys ending with '-comment', such as 'filename-comment', are taken as comments and always allowed.
+	Strict bool
 }
 
 const ConfigFileName = "cfzap"
@@ -51,6 +117,79 @@ func WithFilePaths(filePaths ...string) ConfigPropertySetter {
 	}
 }
 
+// WithWatch set up the Watch property of a ConfigOption object。
+func WithWatch(watch bool) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.Watch = watch
+	}
+}
+
+// WithReloadErrorHandler set up the ReloadErrorHandler property of a ConfigOption object。
+func WithReloadErrorHandler(handler func(error)) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.ReloadErrorHandler = handler
+	}
+}
+
+// WithEnvPrefix set up the EnvPrefix property of a ConfigOption object。
+func WithEnvPrefix(envPrefix string) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.EnvPrefix = envPrefix
+	}
+}
+
+// WithReader set up the Reader and FileExt properties of a ConfigOption object。
+func WithReader(reader io.Reader, fileExt string) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.Reader = reader
+		option.FileExt = fileExt
+	}
+}
+
+// WithData set up the Data and FileExt properties of a ConfigOption object。
+func WithData(data []byte, fileExt string) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.Data = data
+		option.FileExt = fileExt
+	}
+}
+
+// WithFS set up the FS and FSPath properties of a ConfigOption object。
+func WithFS(fsys fs.FS, fsPath string) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.FS = fsys
+		option.FSPath = fsPath
+	}
+}
+
+// WithFilePath set up the FilePath property of a ConfigOption object。
+func WithFilePath(filePath string) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.FilePath = filePath
+	}
+}
+
+// WithProfile set up the Profile property of a ConfigOption object。
+func WithProfile(profile string) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.Profile = profile
+	}
+}
+
+// WithOverlays set up the Overlays property of a ConfigOption object。
+func WithOverlays(overlays ...string) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.Overlays = overlays
+	}
+}
+
+// WithStrict set up the Strict property of a ConfigOption object。
+func WithStrict(strict bool) ConfigPropertySetter {
+	return func(option *ConfigOption) {
+		option.Strict = strict
+	}
+}
+
 // NewConfigOption creates and returns ConfigOption object.
 func NewConfigOption(setters ...ConfigPropertySetter) *ConfigOption {
 	// create default value.
@@ -58,7 +197,9 @@ func NewConfigOption(setters ...ConfigPropertySetter) *ConfigOption {
 		CreateNew: false,
 		FileName:  ConfigFileName,
 		FileExt:   "",
-		FilePaths: []string{"."}}
+		FilePaths: []string{"."},
+		Watch:     false,
+		EnvPrefix: ""}
 
 	// apply settings to properties.
 	for _, setter := range setters {
@@ -68,6 +209,17 @@ func NewConfigOption(setters ...ConfigPropertySetter) *ConfigOption {
 	return option
 }
 
+// cloneConfigOption returns a copy of the ConfigOption object with the setters applied.
+func cloneConfigOption(option *ConfigOption, setters ...ConfigPropertySetter) *ConfigOption {
+	clone := *option
+
+	for _, setter := range setters {
+		setter(&clone)
+	}
+
+	return &clone
+}
+
 func (option *ConfigOption) equal(other *ConfigOption) bool {
 	if option == other {
 		return true
@@ -84,5 +236,52 @@ func (option *ConfigOption) equal(other *ConfigOption) bool {
 	if option.FileExt != other.FileExt {
 		return false
 	}
+	// ReloadErrorHandler is not compared, the cached logger uses the last one given.
+	if option.Watch != other.Watch {
+		return false
+	}
+	if option.EnvPrefix != other.EnvPrefix {
+		return false
+	}
+	if !sameObject(option.Reader, other.Reader) || !sameObject(option.FS, other.FS) {
+		return false
+	}
+	if option.FSPath != other.FSPath || option.FilePath != other.FilePath {
+		return false
+	}
+	if option.Strict != other.Strict {
+		return false
+	}
+	if option.Profile != other.Profile || !reflect.DeepEqual(option.Overlays, other.Overlays) {
+		return false
+	}
+	if (option.Data == nil) != (other.Data == nil) || !bytes.Equal(option.Data, other.Data) {
+		return false
+	}
 	return CompareStringArray(option.FilePaths, other.FilePaths)
 }
+
+// sameObject checks to see if two interface values are the same object.
+// unlike '==', it doesn't panic when the dynamic type is not comparable, such as a map.
+func sameObject(a interface{}, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+
+	switch va.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Func:
+		// they are not comparable, compare the underlying pointers.
+		return va.Pointer() == vb.Pointer()
+	}
+
+	if !va.Type().Comparable() {
+		return false
+	}
+
+	return a == b
+}
@@ -1,6 +1,8 @@
 package cfzap
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,4 +38,28 @@ func TestCompareConfigOption(t *testing.T) {
 	other.CreateNew = false
 	other.FileExt = "ini"
 	assert.False(t, option.equal(other), "property 'FileExt' is different.")
-}
\ No newline at end of file
+}
+
+func TestCompareConfigOptionSource(t *testing.T) {
+	reader := strings.NewReader("appenders: []")
+	option := NewConfigOption(WithReader(reader, "yaml"))
+	assert.True(t, option.equal(NewConfigOption(WithReader(reader, "yaml"))), "same reader should be equal.")
+	assert.False(t, option.equal(NewConfigOption(WithReader(strings.NewReader("appenders: []"), "yaml"))),
+		"different reader should not be equal.")
+
+	option = NewConfigOption(WithData([]byte("appenders: []"), "yaml"))
+	assert.True(t, option.equal(NewConfigOption(WithData([]byte("appenders: []"), "yaml"))), "same data should be equal.")
+	assert.False(t, option.equal(NewConfigOption(WithData([]byte{}, "yaml"))), "different data should not be equal.")
+	assert.False(t, option.equal(NewConfigOption(WithFileExt("yaml"))), "nil data should not be equal.")
+}
+
+func TestConfigOptionProfileFromEnv(t *testing.T) {
+	assert.Nil(t, os.Setenv(ProfileEnvName, "prod"))
+	defer func() { _ = os.Unsetenv(ProfileEnvName) }()
+
+	// the environment variable is read when the config is read, so struct literals work in the same way.
+	assert.Equal(t, "", NewConfigOption().Profile, "the default profile should be empty.")
+	assert.False(t, NewConfigOption().equal(NewConfigOption(WithProfile("dev"))), "property 'Profile' is different.")
+	assert.False(t, NewConfigOption(WithOverlays("a", "b")).equal(NewConfigOption(WithOverlays("b", "a"))),
+		"the order of overlays matters.")
+}
@@ -0,0 +1,192 @@
+package cfzap
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// SchemaID is the $id of the JSON Schema of the config format.
+const SchemaID = "https://github.com/jqk/cfzap/cfzap.schema.json"
+
+// logLevels are the valid values of the levels, in lower case.
+var logLevels = []string{"debug", "info", "warn", "error", "dpanic", "panic", "fatal"}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of the config format, it's same as the file 'cfzap.schema.json'.
+// The schema is generated from the same rules used in strict mode and by Validate().
+// Note, the keys in the schema are case sensitive, but the keys in config file are not.
+func JSONSchema() []byte {
+	schema := map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         SchemaID,
+		"title":       "cfzap config",
+		"description": "The config of the loggers created by cfzap. The other top level keys are the sections referred by name.",
+		"type":        "object",
+		"properties": map[string]interface{}{
+			"appenders": schemaRef("appenderList"),
+			"options":   schemaRef("options"),
+			"levels":    schemaRef("levels"),
+			"loggers": map[string]interface{}{
+				"description":          "The loggers created by GetNamedLogger(), by their names.",
+				"type":                 "object",
+				"additionalProperties": schemaRef("logger"),
+			},
+			includeKey: map[string]interface{}{
+				"description": "The config files included, relative to the including file.",
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+		"patternProperties": schemaCommentKeys(),
+		"additionalProperties": map[string]interface{}{
+			"anyOf": []interface{}{schemaRef("appender"), schemaRef("lumberjack"), schemaRef("encoderConfig")},
+		},
+		"$defs": map[string]interface{}{
+			"appenderList": map[string]interface{}{
+				"description": "The names of the appender sections.",
+				"type":        "array",
+				"minItems":    1,
+				"items":       map[string]interface{}{"type": "string", "minLength": 1},
+			},
+			"logger": schemaObject("A logger in 'loggers' section.", loggerKeys, []string{"appenders"},
+				map[string]interface{}{
+					"appenders": schemaRef("appenderList"),
+					"options":   schemaRef("options"),
+					"levels":    schemaRef("levels"),
+				}),
+			"options": schemaObject("The options of zap.Logger.", optionKeys, nil, map[string]interface{}{
+				"caller":      map[string]interface{}{"type": "boolean"},
+				"development": map[string]interface{}{"type": "boolean"},
+				"fields": map[string]interface{}{
+					"description": "The fields added to all entries.",
+					"type":        "object",
+				},
+			}),
+			"levels": map[string]interface{}{
+				"description": "The levels by logger names, such as 'db.pool', the names can be nested.",
+				"type":        "object",
+				"additionalProperties": map[string]interface{}{
+					"anyOf": []interface{}{schemaEnum(logLevels), schemaRef("levels")},
+				},
+			},
+			"appender": schemaObject("An appender section.", appenderKeys, []string{"target", "encoderConfig"},
+				map[string]interface{}{
+					"target": map[string]interface{}{
+						"description": "'stdout', 'stderr' or the name of a lumberjack section.",
+						"type":        "string",
+						"minLength":   1,
+					},
+					"encoderConfig": map[string]interface{}{
+						"description": "The name of an encoderConfig section.",
+						"type":        "string",
+						"minLength":   1,
+					},
+					"encoderType": schemaEnum(encoderTypes),
+					"logLevel":    schemaEnum(logLevels),
+					"threshold":   schemaEnum(logLevels),
+				}),
+			"lumberjack": schemaObject("A lumberjack section used as target.", lumberjackKeys, []string{"filename"},
+				map[string]interface{}{
+					"filename":   map[string]interface{}{"type": "string", "minLength": 1},
+					"maxSize":    map[string]interface{}{"type": "integer"},
+					"maxAge":     map[string]interface{}{"type": "integer"},
+					"maxBackups": map[string]interface{}{"type": "integer"},
+					"localTime":  map[string]interface{}{"type": "boolean"},
+					"compress":   map[string]interface{}{"type": "boolean"},
+				}),
+			"encoderConfig": schemaObject("An encoderConfig section.", encoderConfigKeys, nil,
+				encoderConfigProperties()),
+		},
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		// the schema is built from maps and slices, it never fails.
+		panic(err)
+	}
+
+	return append(data, '\n')
+}
+
+// encoderConfigProperties returns the properties of encoderConfig section.
+func encoderConfigProperties() map[string]interface{} {
+	properties := make(map[string]interface{})
+	for _, key := range encoderConfigKeys {
+		properties[key] = map[string]interface{}{"type": "string"}
+	}
+
+	properties["encodeLevel"] = schemaEnum(levelEncoders)
+	properties["encodeDuration"] = schemaEnum(durationEncoder)
+	properties["encodeCaller"] = schemaEnum(callerEncoders)
+	properties["encodeName"] = schemaEnum(nameEncoders)
+	properties["encodeTime"] = map[string]interface{}{
+		"anyOf": []interface{}{
+			schemaEnum(timeEncoders),
+			map[string]interface{}{
+				"description": "The customized format starts from '%', such as '%2006-01-02 15:04:05.999'.",
+				"type":        "string",
+				"pattern":     "^%",
+			},
+		},
+	}
+
+	return properties
+}
+
+// schemaObject returns the schema of a section, only the given keys are allowed.
+func schemaObject(description string, keys []string, required []string, properties map[string]interface{}) map[string]interface{} {
+	for _, key := range keys {
+		if _, ok := properties[key]; !ok {
+			// every known key must be described.
+			panic("missing schema of key " + key)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"description":          description,
+		"type":                 "object",
+		"properties":           properties,
+		"patternProperties":    schemaCommentKeys(),
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// schemaCommentKeys returns the pattern properties allowing the keys used as comments, see commentKeySuffix.
+func schemaCommentKeys() map[string]interface{} {
+	return map[string]interface{}{commentKeySuffix + "$": map[string]interface{}{}}
+}
+
+// schemaRef returns the reference to the definition.
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+// schemaEnum returns the schema of a case insensitive enum.
+// JSON Schema has no case insensitive enum, so it's a pattern such as '^([Jj][Ss][Oo][Nn])$'.
+func schemaEnum(values []string) map[string]interface{} {
+	patterns := make([]string, len(values))
+	for i, value := range values {
+		var b strings.Builder
+		for _, r := range value {
+			if unicode.IsLetter(r) {
+				b.WriteString("[" + string(unicode.ToUpper(r)) + string(unicode.ToLower(r)) + "]")
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		patterns[i] = b.String()
+	}
+
+	return map[string]interface{}{
+		"description": "One of " + strings.Join(values, ", ") + ", case insensitive.",
+		"type":        "string",
+		"pattern":     "^(" + strings.Join(patterns, "|") + ")$",
+	}
+}
@@ -0,0 +1,29 @@
+package cfzap
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := os.ReadFile("cfzap.schema.json")
+	assert.Nil(t, err, "fail to read the schema file.")
+	assert.Equal(t, string(JSONSchema()), string(data), "the schema file should be generated by JSONSchema().")
+
+	var schema map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &schema), "the schema should be valid JSON.")
+	assert.Equal(t, SchemaID, schema["$id"])
+
+	defs := schema["$defs"].(map[string]interface{})
+	for _, name := range []string{"appender", "lumberjack", "encoderConfig", "options", "levels", "logger"} {
+		assert.Contains(t, defs, name, "the definition should be in the schema.")
+	}
+}
+
+func TestSchemaEnum(t *testing.T) {
+	enum := schemaEnum([]string{"json", "iso8601"})
+	assert.Equal(t, "^([Jj][Ss][Oo][Nn]|[Ii][Ss][Oo]8601)$", enum["pattern"])
+}
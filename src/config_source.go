@@ -0,0 +1,56 @@
+package cfzap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ConfigSource describes a config file loaded to create a logger.
+type ConfigSource struct {
+	// Path is the absolute path of the config file.
+	// It's the path in fs.FS when the config is read from fs.FS, and empty when it's read from io.Reader or byte slice.
+	Path string
+	// Format is the config type, such as 'yaml' or 'json'.
+	Format string
+	// ModTime is the modification time of the config file.
+	// It's zero when the config is read from io.Reader or byte slice.
+	ModTime time.Time
+	// Hash is the SHA-256 hash of the content in hex.
+	Hash string
+}
+
+// ConfigSourcesOf returns the config files loaded to create the logger.
+// The sources are updated when the logger is created again or reloaded.
+// It returns false if the logger is not created by this package.
+func ConfigSourcesOf(logger *zap.Logger) ([]ConfigSource, bool) {
+	if holder, ok := holderOf(logger); ok {
+		return holder.loadSources(), true
+	}
+
+	return nil, false
+}
+
+// newConfigSource creates and returns ConfigSource object from the content of config file.
+func newConfigSource(path string, format string, modTime time.Time, data []byte) ConfigSource {
+	hash := sha256.Sum256(data)
+
+	return ConfigSource{
+		Path:    path,
+		Format:  format,
+		ModTime: modTime,
+		Hash:    hex.EncodeToString(hash[:]),
+	}
+}
+
+// fields returns the zap fields describing the source.
+func (source ConfigSource) fields() []zap.Field {
+	return []zap.Field{
+		zap.String("path", source.Path),
+		zap.String("format", source.Format),
+		zap.Time("modTime", source.ModTime),
+		zap.String("hash", source.Hash),
+	}
+}
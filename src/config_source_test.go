@@ -0,0 +1,64 @@
+package cfzap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestReadConfigSource(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testFilePath, "cfzap.json"))
+	assert.Nil(t, err, "fail to read test file.")
+	hash := sha256.Sum256(data)
+	expectedHash := hex.EncodeToString(hash[:])
+	expectedPath, _ := filepath.Abs(filepath.Join(testFilePath, "cfzap.json"))
+
+	// the json file is picked when no extension is given.
+	_, sources, err := readConfigFile(NewConfigOption(WithFileExt(""), WithFilePaths(testFilePath)))
+	assert.Nil(t, err, "fail to search config file.")
+	assert.Equal(t, 1, len(sources), "one config file should be loaded.")
+	assert.Equal(t, expectedPath, sources[0].Path, "the absolute path should be reported.")
+	assert.Equal(t, "json", sources[0].Format, "the format should be decided by the file extension.")
+	assert.False(t, sources[0].ModTime.IsZero(), "the modification time should be reported.")
+	assert.Equal(t, expectedHash, sources[0].Hash, "the hash should be SHA-256 of the content.")
+
+	_, sources, err = readConfigFile(NewConfigOption(WithFilePath(filepath.Join(testFilePath, "cfzap.json"))))
+	assert.Nil(t, err, "fail to read config file by path.")
+	assert.Equal(t, expectedPath, sources[0].Path, "the absolute path should be reported.")
+
+	// the content has no path nor modification time.
+	_, sources, err = readConfigFile(NewConfigOption(WithData(data, "json")))
+	assert.Nil(t, err, "fail to read config from byte slice.")
+	assert.Equal(t, "", sources[0].Path, "byte slice has no path.")
+	assert.True(t, sources[0].ModTime.IsZero(), "byte slice has no modification time.")
+	assert.Equal(t, expectedHash, sources[0].Hash, "the hash should be SHA-256 of the content.")
+
+	_, sources, err = readConfigFile(NewConfigOption(WithFS(testFS, "test_config_file/cfzap.json")))
+	assert.Nil(t, err, "fail to read config from fs.FS.")
+	assert.Equal(t, "test_config_file/cfzap.json", sources[0].Path, "the path in fs.FS should be reported.")
+	assert.Equal(t, "json", sources[0].Format, "the format should be decided by the file extension.")
+	assert.Equal(t, expectedHash, sources[0].Hash, "the hash should be SHA-256 of the content.")
+}
+
+func TestConfigSourcesOf(t *testing.T) {
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	logger, err := r.GetLogger(NewConfigOption(WithFileName("logger_config"), WithFileExt("yaml"), WithFilePaths(testFilePath)))
+	assert.Nil(t, err, "fail to create a new logger from logger_config.yaml.")
+
+	sources, ok := ConfigSourcesOf(logger.Named("child"))
+	assert.True(t, ok, "the logger should have config sources.")
+	assert.Equal(t, 1, len(sources), "one config file should be loaded.")
+	assert.Equal(t, "logger_config.yaml", filepath.Base(sources[0].Path), "the loaded file should be reported.")
+	assert.Equal(t, "yaml", sources[0].Format, "the format should be reported.")
+
+	_, ok = ConfigSourcesOf(zap.NewNop())
+	assert.False(t, ok, "the logger not created by cfzap should not have config sources.")
+}
@@ -0,0 +1,371 @@
+package cfzap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// The names of the templates of GenerateConfig().
+const (
+	// TemplateDevelopment writes colored, human readable lines to the console at debug level.
+	TemplateDevelopment = "dev"
+	// TemplateProduction writes JSON lines to a rotating file at info level.
+	TemplateProduction = "prod"
+	// TemplateContainer writes JSON lines to stdout only, for the log collector of the container platform.
+	TemplateContainer = "container"
+)
+
+// ConfigTemplate describes a template of GenerateConfig().
+type ConfigTemplate struct {
+	// Name is the name passed to GenerateConfig(), such as TemplateProduction.
+	Name string
+	// Description is a one line description of the template.
+	Description string
+}
+
+// templateItem is a key of the config generated from template, with its comment.
+type templateItem struct {
+	key string
+	// the comment lines written above the key, they are not written in JSON.
+	comment []string
+	// a string, bool, int, []string, or []templateItem for a section.
+	value interface{}
+}
+
+// configTemplate is a template of the config file.
+type configTemplate struct {
+	ConfigTemplate
+	items []templateItem
+}
+
+// encoderComment is the comment of all encoderConfig sections in templates.
+var encoderComment = []string{
+	"the keys of each log entry, the entry omits the portion whose key is empty.",
+	"see https://pkg.go.dev/go.uber.org/zap@v1.17.0/zapcore#EncoderConfig",
+}
+
+// optionsComment is the comment of all options sections in templates.
+var optionsComment = []string{"see https://pkg.go.dev/go.uber.org/zap#Option"}
+
+// configTemplates are the templates of GenerateConfig(), in the order of ConfigTemplates().
+var configTemplates = []configTemplate{
+	{
+		ConfigTemplate: ConfigTemplate{Name: TemplateDevelopment,
+			Description: "local development: colored, human readable lines on the console at debug level"},
+		items: []templateItem{
+			{key: "options", comment: optionsComment, value: []templateItem{
+				{key: "caller", comment: []string{"annotates each entry with the file and line of the caller."},
+					value: true},
+				{key: "development", comment: []string{"DPanic level entries panic in development mode."},
+					value: true},
+			}},
+			{key: "appenders", comment: []string{"the appenders the entries are written to, each one is a section below."},
+				value: []string{"appender-console"}},
+			{key: "appender-console", comment: []string{"writes all entries to the console."}, value: []templateItem{
+				{key: "target", comment: []string{"'stdout', 'stderr' or the name of a lumberjack section."},
+					value: "stdout"},
+				{key: "encoderType", comment: []string{"'console' or 'json'."}, value: "console"},
+				{key: "logLevel", comment: []string{"the lowest level written, 'debug', 'info', 'warn' or 'error'."},
+					value: "debug"},
+				{key: "encoderConfig", comment: []string{"the name of the encoderConfig section."},
+					value: "encoder-console"},
+			}},
+			{key: "encoder-console", comment: encoderComment, value: []templateItem{
+				{key: "messageKey", value: "msg"},
+				{key: "levelKey", value: "level"},
+				{key: "timeKey", value: "time"},
+				{key: "nameKey", value: "logger"},
+				{key: "callerKey", value: "caller"},
+				{key: "stacktraceKey", value: "stacktrace"},
+				{key: "consoleSeparator", comment: []string{"the separator of the fields, it's tab by default."},
+					value: " "},
+				{key: "encodeLevel", comment: []string{"'capital', 'color' or 'lowercase'."}, value: "color"},
+				{key: "encodeTime", comment: []string{"customized format starts from '%', in the layout of Go."},
+					value: "%15:04:05.000"},
+				{key: "encodeCaller", comment: []string{"'short' or 'full'."}, value: "short"},
+			}},
+		},
+	},
+	{
+		ConfigTemplate: ConfigTemplate{Name: TemplateProduction,
+			Description: "production: JSON lines to a rotating file at info level"},
+		items: []templateItem{
+			{key: "options", comment: optionsComment, value: []templateItem{
+				{key: "caller", comment: []string{"annotates each entry with the file and line of the caller."},
+					value: true},
+				{key: "development", value: false},
+			}},
+			{key: "appenders", comment: []string{"the appenders the entries are written to, each one is a section below."},
+				value: []string{"appender-file"}},
+			{key: "appender-file", comment: []string{"writes JSON lines to the rotating file."}, value: []templateItem{
+				{key: "target", comment: []string{"the name of the lumberjack section below."},
+					value: "lumberjack-file"},
+				{key: "encoderType", value: "json"},
+				{key: "logLevel", comment: []string{
+					"the lowest level written, it can be overridden by environment variable,",
+					"such as CFZAP_APPENDER_FILE_LOGLEVEL=debug with WithEnvPrefix(\"CFZAP\").",
+				}, value: "info"},
+				{key: "encoderConfig", value: "encoder-json"},
+			}},
+			{key: "lumberjack-file", comment: []string{
+				"the rotating file, see https://pkg.go.dev/gopkg.in/natefinch/lumberjack.v2#Logger",
+			}, value: []templateItem{
+				{key: "filename", comment: []string{
+					"${exe} is the executable name, ${LOG_DIR:-logs} is the environment variable LOG_DIR",
+					"or 'logs' if it's not set. the directory is created when the logger is created.",
+				}, value: "${LOG_DIR:-logs}/${exe}.log"},
+				{key: "maxSize", comment: []string{"the size in megabytes of the file before it's rotated."},
+					value: 100},
+				{key: "maxAge", comment: []string{"the days to retain the rotated files, 0 retains them forever."},
+					value: 30},
+				{key: "maxBackups", comment: []string{"the number of the rotated files to retain, 0 retains all."},
+					value: 10},
+				{key: "localTime", comment: []string{"names the rotated files in local time instead of UTC."},
+					value: false},
+				{key: "compress", comment: []string{"compresses the rotated files by gzip."}, value: true},
+			}},
+			{key: "encoder-json", comment: encoderComment, value: []templateItem{
+				{key: "messageKey", value: "msg"},
+				{key: "levelKey", value: "level"},
+				{key: "timeKey", value: "ts"},
+				{key: "nameKey", value: "logger"},
+				{key: "callerKey", value: "caller"},
+				{key: "stacktraceKey", value: "stacktrace"},
+				{key: "encodeLevel", value: "lowercase"},
+				{key: "encodeTime", comment: []string{"'iso8601', 'rfc3339', 'rfc3339nano', 'millis', 'nanos' or 'epoch'."},
+					value: "iso8601"},
+				{key: "encodeDuration", comment: []string{"'string', 'ms', 'nanos' or 'seconds'."}, value: "ms"},
+				{key: "encodeCaller", value: "short"},
+			}},
+		},
+	},
+	{
+		ConfigTemplate: ConfigTemplate{Name: TemplateContainer,
+			Description: "container: JSON lines to stdout only, for the log collector of the platform"},
+		items: []templateItem{
+			{key: "options", comment: optionsComment, value: []templateItem{
+				{key: "caller", value: true},
+				{key: "fields", comment: []string{"the fields added to all entries, to tell the pods apart."},
+					value: []templateItem{
+						{key: "host", value: "${hostname}"},
+					}},
+			}},
+			{key: "appenders", comment: []string{
+				"the container platform collects stdout, so no file is written.",
+			}, value: []string{"appender-stdout"}},
+			{key: "appender-stdout", value: []templateItem{
+				{key: "target", value: "stdout"},
+				{key: "encoderType", value: "json"},
+				{key: "logLevel", comment: []string{
+					"set by environment variable LOG_LEVEL in the deployment, 'info' if it's not set.",
+				}, value: "${LOG_LEVEL:-info}"},
+				{key: "encoderConfig", value: "encoder-json"},
+			}},
+			{key: "encoder-json", comment: encoderComment, value: []templateItem{
+				{key: "messageKey", value: "msg"},
+				{key: "levelKey", value: "level"},
+				{key: "timeKey", value: "ts"},
+				{key: "nameKey", value: "logger"},
+				{key: "callerKey", value: "caller"},
+				{key: "stacktraceKey", value: "stacktrace"},
+				{key: "encodeLevel", value: "lowercase"},
+				{key: "encodeTime", value: "rfc3339nano"},
+				{key: "encodeDuration", value: "ms"},
+				{key: "encodeCaller", value: "short"},
+			}},
+		},
+	},
+}
+
+// ConfigTemplates returns the templates of GenerateConfig().
+func ConfigTemplates() []ConfigTemplate {
+	templates := make([]ConfigTemplate, len(configTemplates))
+	for i, t := range configTemplates {
+		templates[i] = t.ConfigTemplate
+	}
+
+	return templates
+}
+
+// GenerateFormats returns the formats supported by GenerateConfig(): 'json', 'toml', 'yaml' and 'yml'.
+func GenerateFormats() []string {
+	return append([]string(nil), encodeFormats...)
+}
+
+// GenerateConfig returns a commented starter config file from template, one of TemplateDevelopment,
+// TemplateProduction and TemplateContainer. format is one of 'json', 'toml', 'yaml' and 'yml',
+// see GenerateFormats(), JSON has no comment. The other formats supported by viper, that are 'properties',
+// 'props', 'prop', 'hcl', 'dotenv', 'env' and 'ini', cannot hold the list of 'appenders' or the nested
+// sections, so they are rejected with an error.
+// The config passes Validate(), and is the start point to be edited for the service.
+func GenerateConfig(template string, format string) ([]byte, error) {
+	var t *configTemplate
+	names := make([]string, len(configTemplates))
+	for i := range configTemplates {
+		names[i] = configTemplates[i].Name
+		if strings.EqualFold(configTemplates[i].Name, strings.TrimSpace(template)) {
+			t = &configTemplates[i]
+		}
+	}
+	if t == nil {
+		return nil, fmt.Errorf("unknown template [%s], it should be one of [%s]", template, strings.Join(names, ", "))
+	}
+
+	header := []string{
+		"starter config of cfzap for " + t.Description + ".",
+		"it's generated by cfzap.GenerateConfig(\"" + t.Name + "\"), see 'cfzap.yaml' of cfzap for all keys.",
+	}
+
+	var b bytes.Buffer
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "json":
+		writeJSONSection(&b, t.items, "")
+		b.WriteString("\n")
+	case "toml":
+		writeComment(&b, header, "")
+		b.WriteString("\n")
+		writeTOMLSection(&b, t.items, "")
+	case "yaml", "yml":
+		b.WriteString("---\n")
+		writeComment(&b, header, "")
+		writeYAMLSection(&b, t.items, "")
+	default:
+		if StringInArray(f, viper.SupportedExts) {
+			return nil, fmt.Errorf("format [%s] cannot hold the config of cfzap, it should be one of [%s]", format,
+				strings.Join(encodeFormats, ", "))
+		}
+		return nil, fmt.Errorf("unsupported format [%s], it should be one of [%s]", format,
+			strings.Join(encodeFormats, ", "))
+	}
+
+	return b.Bytes(), nil
+}
+
+// writeComment writes the comment lines with the indent.
+func writeComment(b *bytes.Buffer, comment []string, indent string) {
+	for _, line := range comment {
+		b.WriteString(indent + "# " + line + "\n")
+	}
+}
+
+// writeYAMLSection writes the items of a section in YAML, the top level sections are separated by blank lines.
+func writeYAMLSection(b *bytes.Buffer, items []templateItem, indent string) {
+	for _, item := range items {
+		if indent == "" {
+			b.WriteString("\n")
+		}
+		writeComment(b, item.comment, indent)
+
+		switch v := item.value.(type) {
+		case []templateItem:
+			b.WriteString(indent + item.key + ":\n")
+			writeYAMLSection(b, v, indent+"  ")
+		case []string:
+			b.WriteString(indent + item.key + ":\n")
+			for _, s := range v {
+				b.WriteString(indent + "- " + yamlScalar(s) + "\n")
+			}
+		default:
+			b.WriteString(indent + item.key + ": " + yamlScalar(v) + "\n")
+		}
+	}
+}
+
+// yamlScalar returns the scalar value in YAML, the strings are quoted when required.
+func yamlScalar(value interface{}) string {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		// the values in templates are strings, bools and ints only.
+		panic(err)
+	}
+
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// writeTOMLSection writes the items of the table named by prefix in TOML, the top level table has no name.
+// the values come first, then the sub tables, as required by TOML.
+func writeTOMLSection(b *bytes.Buffer, items []templateItem, prefix string) {
+	for _, item := range items {
+		if _, ok := item.value.([]templateItem); ok {
+			continue
+		}
+
+		writeComment(b, item.comment, "")
+		b.WriteString(tomlKey(item.key) + " = " + tomlValue(item.value) + "\n")
+	}
+
+	for _, item := range items {
+		section, ok := item.value.([]templateItem)
+		if !ok {
+			continue
+		}
+
+		name := tomlKey(item.key)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		b.WriteString("\n")
+		writeComment(b, item.comment, "")
+		b.WriteString("[" + name + "]\n")
+		writeTOMLSection(b, section, name)
+	}
+}
+
+// bareTOMLKey matches the keys which need no quotes in TOML.
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// tomlKey returns the key in TOML, it's quoted when required.
+func tomlKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}
+
+// tomlValue returns the value in TOML.
+// the strings in templates are ASCII, so they are quoted in the same way as Go.
+func tomlValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = strconv.Quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeJSONSection writes the items of a section as a JSON object, the keys are kept in the order of template.
+func writeJSONSection(b *bytes.Buffer, items []templateItem, indent string) {
+	b.WriteString("{\n")
+	for i, item := range items {
+		key, _ := json.Marshal(item.key)
+		b.WriteString(indent + "  " + string(key) + ": ")
+
+		if section, ok := item.value.([]templateItem); ok {
+			writeJSONSection(b, section, indent+"  ")
+		} else {
+			value, _ := json.Marshal(item.value)
+			b.Write(value)
+		}
+
+		if i < len(items)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
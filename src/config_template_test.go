@@ -0,0 +1,89 @@
+package cfzap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestGenerateConfig(t *testing.T) {
+	templates := ConfigTemplates()
+	assert.Equal(t, 3, len(templates))
+	assert.Equal(t, TemplateDevelopment, templates[0].Name)
+	assert.Equal(t, TemplateProduction, templates[1].Name)
+	assert.Equal(t, TemplateContainer, templates[2].Name)
+
+	for _, template := range templates {
+		for _, format := range []string{"yaml", "yml", "toml", "json"} {
+			data, err := GenerateConfig(template.Name, format)
+			assert.Nil(t, err, "fail to generate [%s] in %s.", template.Name, format)
+			assert.Nil(t, Validate(NewConfigOption(WithData(data, format))),
+				"the config [%s] in %s should be valid.", template.Name, format)
+
+			if format != "json" {
+				assert.True(t, strings.Contains(string(data), "# starter config of cfzap for "+template.Description),
+					"the config [%s] in %s should be commented.", template.Name, format)
+			}
+		}
+	}
+
+	// the templates are case insensitive.
+	data, err := GenerateConfig("PROD", "yaml")
+	assert.Nil(t, err, "fail to generate [PROD].")
+	assert.Contains(t, string(data), "  # the size in megabytes of the file before it's rotated.\n  maxSize: 100\n")
+	assert.Contains(t, string(data), "  encodeTime: iso8601\n")
+}
+
+func TestGenerateConfigLoggers(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.Setenv("LOG_DIR", dir))
+	defer func() { _ = os.Unsetenv("LOG_DIR") }()
+
+	data, _ := GenerateConfig(TemplateProduction, "toml")
+	description, err := DescribeLogger(NewConfigOption(WithData(data, "toml")), "")
+	assert.Nil(t, err, "fail to describe [prod].")
+	assert.Equal(t, TargetFile, description.Appenders[0].TargetKind)
+	assert.True(t, strings.HasPrefix(description.Appenders[0].File.Filename, filepath.ToSlash(dir)+"/"))
+	assert.Equal(t, 100, description.Appenders[0].File.MaxSize)
+	assert.True(t, description.Appenders[0].File.Compress)
+
+	data, _ = GenerateConfig(TemplateDevelopment, "json")
+	description, err = DescribeLogger(NewConfigOption(WithData(data, "json")), "")
+	assert.Nil(t, err, "fail to describe [dev].")
+	assert.Equal(t, zapcore.DebugLevel, description.Appenders[0].Level)
+	assert.Equal(t, "color", description.Appenders[0].Encoder.EncodeLevel)
+	assert.True(t, description.Options.Development)
+
+	data, _ = GenerateConfig(TemplateContainer, "yaml")
+	logger, err := GetLogger(NewConfigOption(WithData(data, "yaml"), WithCreateNew(true)))
+	assert.Nil(t, err, "fail to create logger from [container].")
+	assert.True(t, logger.Core().Enabled(zapcore.InfoLevel))
+	assert.False(t, logger.Core().Enabled(zapcore.DebugLevel))
+}
+
+func TestGenerateConfigErrors(t *testing.T) {
+	_, err := GenerateConfig("staging", "yaml")
+	assert.Equal(t, "unknown template [staging], it should be one of [dev, prod, container]", err.Error())
+
+	_, err = GenerateConfig("dev", "properties")
+	assert.Equal(t, "format [properties] cannot hold the config of cfzap, it should be one of [json, toml, yaml, yml]",
+		err.Error())
+
+	_, err = GenerateConfig("dev", "xml")
+	assert.Equal(t, "unsupported format [xml], it should be one of [json, toml, yaml, yml]", err.Error())
+
+	// each format of viper is either generated, or rejected as it cannot hold the config.
+	assert.Equal(t, []string{"json", "toml", "yaml", "yml"}, GenerateFormats())
+	for _, format := range viper.SupportedExts {
+		if _, err = GenerateConfig("dev", format); StringInArray(format, GenerateFormats()) {
+			assert.Nil(t, err, "the config should be generated in "+format)
+		} else {
+			assert.Contains(t, err.Error(), "cannot hold the config of cfzap", "the format should be rejected: "+format)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package cfzap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+)
+
+// the known keys of each kind of section, they are used to find the unknown keys in strict mode.
+var (
+	topLevelKeys      = []string{"appenders", "options", "levels", "loggers", includeKey}
+	loggerKeys        = []string{"appenders", "options", "levels"}
+	optionKeys        = []string{"caller", "development", "fields"}
+	appenderKeys      = []string{"target", "encoderConfig", "encoderType", "logLevel", "threshold"}
+	lumberjackKeys    = []string{"filename", "maxSize", "maxAge", "maxBackups", "localTime", "compress"}
+	encoderConfigKeys = []string{"messageKey", "levelKey", "timeKey", "nameKey", "callerKey", "functionKey",
+		"stacktraceKey", "lineEnding", "consoleSeparator",
+		"encodeLevel", "encodeTime", "encodeDuration", "encodeCaller", "encodeName"}
+)
+
+// the valid values of the enum keys, in lower case.
+// the values are lower cased before they are parsed, so only the lower case values of zap are valid.
+var (
+	encoderTypes    = []string{"console", "json"}
+	levelEncoders   = []string{"capital", "color", "lowercase"}
+	timeEncoders    = []string{"rfc3339nano", "rfc3339", "iso8601", "millis", "nanos", "epoch"}
+	durationEncoder = []string{"string", "nanos", "ms", "seconds"}
+	callerEncoders  = []string{"full", "short"}
+	nameEncoders    = []string{"full"}
+)
+
+// commentKeySuffix marks the keys used as comments, such as 'filename-comment', since JSON has no comments.
+// they are allowed in any section.
+const commentKeySuffix = "-comment"
+
+// maxTypoDistance is the max edit distance between an unknown key and the known key it's taken as a typo of.
+const maxTypoDistance = 2
+
+// configValidator checks the config strictly and collects all problems found.
+type configValidator struct {
+	config *viper.Viper
+	// the errors found, the keys in them are in lower case.
+	problems []error
+	// the sections checked already in lower case, the sections shared by appenders are checked only once.
+	checked map[string]bool
+}
+
+// Problem is a problem found in the config by Validate().
+type Problem struct {
+	// Key is the full dotted path of the key causing the problem,
+	// it's empty if the problem is not caused by a key, such as the config file is not found.
+	Key string
+	// Message describes the problem.
+	Message string
+	// Err is the error of the problem, such as *UnknownKeyError or *InvalidValueError.
+	Err error
+}
+
+// Validate reads the config specified by option, and checks it against the rules in strict mode,
+// which are also described by JSONSchema(). No writer is built and no directory is created.
+// It returns all problems found sorted by key, or nil when the config is valid.
+// Note, the reader in option is consumed.
+func Validate(option *ConfigOption) []Problem {
+	if option == nil { // using default value if it is not provided.
+		option = NewConfigOption()
+	}
+
+	// the problems are returned by readConfig() instead of as error.
+	_, _, errs, err := readConfig(option, true)
+	if err != nil {
+		return []Problem{{Message: err.Error(), Err: err}}
+	}
+
+	var problems []Problem
+	for _, err := range errs {
+		problems = append(problems, Problem{Key: errorKey(err), Message: err.Error(), Err: err})
+	}
+
+	return problems
+}
+
+// checkConfig checks all the sections used by the top level logger and the loggers in 'loggers' section.
+// It reports the unknown keys, the invalid enum values, and the values which are not a number or a bool as expected.
+// It returns all problems found, sorted by key.
+func checkConfig(config *viper.Viper) []error {
+	v := &configValidator{config: config, checked: make(map[string]bool)}
+
+	settings := config.AllSettings()
+	if config.IsSet("appenders") {
+		v.checkLogger("")
+	}
+
+	if config.IsSet(loggersSection) {
+		if _, ok := settings[loggersSection].(map[string]interface{}); !ok {
+			v.add(&InvalidValueError{Key: loggersSection, Value: settings[loggersSection], Reason: "it should be a section"})
+		}
+		for _, name := range definedLoggerNames(config) {
+			v.checkKeys(loggersSection+"."+name, loggerKeys)
+			v.checkLogger(name)
+		}
+	}
+
+	for key := range settings {
+		// the other top level keys are sections referred by name, they are checked when they are used.
+		// an unused one is only reported if it looks like a misspelled known key.
+		if !v.checked[key] && !containsFold(topLevelKeys, key) && !isCommentKey(key) {
+			if known := suggestKey(key, topLevelKeys); known != "" {
+				v.add(&UnknownKeyError{Key: key, Suggestion: known})
+			}
+		}
+	}
+
+	sortProblems(v.problems)
+
+	return v.problems
+}
+
+// sortProblems sorts the problems by key, it's case insensitive.
+func sortProblems(problems []error) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		return strings.ToLower(errorKey(problems[i])) < strings.ToLower(errorKey(problems[j]))
+	})
+}
+
+// add adds a problem.
+func (v *configValidator) add(err error) {
+	v.problems = append(v.problems, err)
+}
+
+// checkLogger checks the appenders, options and levels of the logger.
+func (v *configValidator) checkLogger(loggerName string) {
+	appendersKey := loggerSectionName(loggerName, "appenders")
+	// the same as describeAppenderList(), the list is taken as it's loaded.
+	items, ok := v.config.Get(appendersKey).([]interface{})
+	if !ok || len(items) == 0 {
+		v.add(&InvalidValueError{Key: appendersKey, Value: v.config.Get(appendersKey),
+			Reason: "it should be a non empty list of appender names"})
+	}
+	for i, item := range items {
+		if name, err := appenderName(appendersKey, i, item); err != nil {
+			v.add(err)
+		} else {
+			v.checkAppender(name)
+		}
+	}
+
+	if optionsKey := loggerSectionName(loggerName, "options"); v.config.IsSet(optionsKey) {
+		v.checkOptions(optionsKey)
+	}
+
+	if levelsKey := loggerSectionName(loggerName, "levels"); v.config.IsSet(levelsKey) {
+		values, ok := v.config.Get(levelsKey).(map[string]interface{})
+		if !ok {
+			v.add(&InvalidValueError{Key: levelsKey, Value: v.config.Get(levelsKey), Reason: "it should be a section"})
+			return
+		}
+		for name, err := range addNamedLevels(make(map[string]zapcore.Level), "", values) {
+			key := levelsKey + "." + name
+			v.add(&InvalidValueError{Key: key, Value: v.config.Get(key), Reason: err.Error()})
+		}
+	}
+}
+
+// checkOptions checks the options section.
+func (v *configValidator) checkOptions(key string) {
+	if !v.checkKeys(key, optionKeys) {
+		return
+	}
+
+	v.checkBool(key + ".caller")
+	v.checkBool(key + ".development")
+	if fieldsKey := key + ".fields"; v.config.IsSet(fieldsKey) && v.config.Sub(fieldsKey) == nil {
+		v.add(&InvalidValueError{Key: fieldsKey, Value: v.config.Get(fieldsKey), Reason: "it should be a section"})
+	}
+}
+
+// checkAppender checks the appender section and the sections it refers.
+func (v *configValidator) checkAppender(name string) {
+	if v.checked[strings.ToLower(name)] {
+		return
+	}
+	v.checked[strings.ToLower(name)] = true
+
+	if !v.config.IsSet(name) {
+		v.add(&MissingSectionError{Key: name})
+		return
+	}
+	if !v.checkKeys(name, appenderKeys) {
+		return
+	}
+
+	if target, ok := v.checkRequiredString(name + ".target"); ok &&
+		!strings.EqualFold(target, "stdout") && !strings.EqualFold(target, "stderr") {
+		v.checkLumberjack(name, target)
+	}
+	if encoderConfig, ok := v.checkRequiredString(name + ".encoderConfig"); ok {
+		v.checkEncoderConfig(name+".encoderConfig", encoderConfig)
+	}
+
+	v.checkEnum(name+".encoderType", encoderTypes)
+	for _, key := range []string{name + ".logLevel", name + ".threshold"} {
+		if !v.config.IsSet(key) {
+			continue
+		}
+		var level zapcore.Level
+		if err := level.UnmarshalText(getLowerBytes(v.config, key)); err != nil {
+			v.add(&InvalidValueError{Key: key, Value: v.config.Get(key), Reason: err.Error()})
+		}
+	}
+}
+
+// checkLumberjack checks the lumberjack section used by the appender.
+func (v *configValidator) checkLumberjack(appender string, name string) {
+	if v.checked[strings.ToLower(name)] {
+		return
+	}
+	v.checked[strings.ToLower(name)] = true
+
+	if !v.config.IsSet(name) {
+		v.add(&UnknownTargetError{Appender: appender, Target: name})
+		return
+	}
+	if !v.checkKeys(name, lumberjackKeys) {
+		return
+	}
+
+	v.checkRequiredString(name + ".filename")
+	v.checkInt(name + ".maxSize")
+	v.checkInt(name + ".maxAge")
+	v.checkInt(name + ".maxBackups")
+	v.checkBool(name + ".localTime")
+	v.checkBool(name + ".compress")
+}
+
+// checkEncoderConfig checks the encoderConfig section referred by the key.
+func (v *configValidator) checkEncoderConfig(referrer string, name string) {
+	if v.checked[strings.ToLower(name)] {
+		return
+	}
+	v.checked[strings.ToLower(name)] = true
+
+	if !v.config.IsSet(name) {
+		v.add(&MissingSectionError{Key: name, Referrer: referrer})
+		return
+	}
+	if !v.checkKeys(name, encoderConfigKeys) {
+		return
+	}
+
+	v.checkEnum(name+".encodeLevel", levelEncoders)
+	v.checkEnum(name+".encodeDuration", durationEncoder)
+	v.checkEnum(name+".encodeCaller", callerEncoders)
+	v.checkEnum(name+".encodeName", nameEncoders)
+	// customized format starts from '%'.
+	if key := name + ".encodeTime"; !strings.HasPrefix(v.config.GetString(key), "%") {
+		v.checkEnum(key, timeEncoders)
+	}
+}
+
+// checkKeys checks to see if all keys of the section are known.
+// it returns false if the value is not a section.
+func (v *configValidator) checkKeys(key string, known []string) bool {
+	section, ok := v.config.Get(key).(map[string]interface{})
+	if !ok {
+		v.add(&InvalidValueError{Key: key, Value: v.config.Get(key), Reason: "it should be a section"})
+		return false
+	}
+
+	for k := range section {
+		if containsFold(known, k) || isCommentKey(k) {
+			continue
+		}
+
+		v.add(&UnknownKeyError{Key: key + "." + k, Suggestion: suggestKey(k, known)})
+	}
+
+	return true
+}
+
+// checkRequiredString checks to see if the value is a non empty string.
+// it returns the value and true if it is.
+func (v *configValidator) checkRequiredString(key string) (string, bool) {
+	if !v.config.IsSet(key) {
+		v.add(&MissingKeyError{Key: key})
+		return "", false
+	}
+
+	value, ok := v.config.Get(key).(string)
+	if !ok {
+		v.add(&InvalidValueError{Key: key, Value: v.config.Get(key), Reason: "it should be a string"})
+		return "", false
+	}
+	if value = strings.TrimSpace(value); value == "" {
+		v.add(&InvalidValueError{Key: key, Value: value, Reason: "it's empty"})
+		return "", false
+	}
+
+	return value, true
+}
+
+// checkEnum checks to see if the value is one of the valid values, it's case insensitive.
+func (v *configValidator) checkEnum(key string, values []string) {
+	if !v.config.IsSet(key) {
+		return
+	}
+
+	value, ok := v.config.Get(key).(string)
+	if !ok || !StringInArray(strings.ToLower(strings.TrimSpace(value)), values) {
+		v.add(&InvalidValueError{Key: key, Value: v.config.Get(key),
+			Reason: fmt.Sprintf("it should be one of [%s]", strings.Join(values, ", "))})
+	}
+}
+
+// checkInt checks to see if the value is an integer.
+func (v *configValidator) checkInt(key string) {
+	if !v.config.IsSet(key) {
+		return
+	}
+
+	value := v.config.Get(key)
+	if f, ok := value.(float64); ok && f != math.Trunc(f) {
+		v.add(&InvalidValueError{Key: key, Value: value, Reason: "it should be an integer"})
+		return
+	}
+	if _, err := cast.ToIntE(value); err != nil {
+		v.add(&InvalidValueError{Key: key, Value: value, Reason: "it should be an integer"})
+	}
+}
+
+// checkBool checks to see if the value is a bool.
+func (v *configValidator) checkBool(key string) {
+	if !v.config.IsSet(key) {
+		return
+	}
+
+	value := v.config.Get(key)
+	if _, err := cast.ToBoolE(value); err != nil {
+		v.add(&InvalidValueError{Key: key, Value: value, Reason: "it should be true or false"})
+	}
+}
+
+// containsFold checks to see if the list contains the value, it's case insensitive.
+func containsFold(list []string, value string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCommentKey checks to see if the key is used as a comment, it's case insensitive.
+func isCommentKey(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), commentKeySuffix)
+}
+
+// suggestKey returns the known key which the unknown key is most likely a typo of.
+// it returns empty string if no known key is close enough.
+func suggestKey(key string, known []string) string {
+	suggestion := ""
+	best := maxTypoDistance + 1
+
+	for _, k := range known {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(k)); d < best {
+			suggestion, best = k, d
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// minInt returns the smaller one of a and b.
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
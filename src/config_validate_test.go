@@ -0,0 +1,129 @@
+package cfzap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	option := NewConfigOption(WithFileName("strict_config"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+	config, _, err := readConfigFile(option)
+	assert.Nil(t, err, "the config should be read in non strict mode.")
+
+	problems := checkConfig(config)
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Error()
+	}
+
+	assert.Equal(t, []string{
+		"the value [xml] of [appender-file.encoderType] is invalid: it should be one of [console, json]",
+		"the value [capitol] of [encoderConfig.encodeLevel] is invalid: it should be one of [capital, color, lowercase]",
+		"unknown key [encoderConfig.encodtime], did you mean [encodeTime]?",
+		"the value [maybe] of [lumberjack2.compress] is invalid: it should be true or false",
+		"the value [ten] of [lumberjack2.maxAge] is invalid: it should be an integer",
+		"unknown key [lumberjack2.maxsize2], did you mean [maxSize]?",
+		"unknown key [optoins], did you mean [options]?",
+	}, messages, "all problems should be reported.")
+
+	_, _, err = readConfigFile(cloneConfigOption(option, WithStrict(true)))
+	assert.NotNil(t, err, "the problems should be reported in strict mode.")
+	var configErrors *ConfigErrors
+	assert.True(t, errors.As(err, &configErrors), "all problems should be returned.")
+	assert.Equal(t, len(problems), len(configErrors.Errors), "all problems should be returned.")
+	var unknownKeyErr *UnknownKeyError
+	assert.True(t, errors.As(err, &unknownKeyErr), "the unknown key should be reported.")
+	assert.Equal(t, "encoderConfig.encodtime", unknownKeyErr.Key, "the first unknown key should be found.")
+
+	// the sample config file is valid.
+	_, _, err = readConfigFile(NewConfigOption(WithFilePath("cfzap.yaml"), WithStrict(true)))
+	assert.Nil(t, err, "the sample config file should be valid.")
+}
+
+func TestValidateLoggers(t *testing.T) {
+	option := NewConfigOption(WithFilePath(filepath.Join(testFilePath, "logger_config.yaml")), WithStrict(true))
+	_, _, err := readConfigFile(option)
+	assert.NotNil(t, err, "the missing appender of logger should be reported.")
+	assert.Equal(t, "1 error in config: missing section [appender-missing]", err.Error(), "not expected error.")
+
+	r := NewRegistry()
+	logger, err := r.GetLogger(option)
+	assert.NotNil(t, err, "no logger should be created in strict mode.")
+	assert.Same(t, defaultLogger, logger, "the default logger should be returned.")
+}
+
+func TestSuggestKey(t *testing.T) {
+	assert.Equal(t, "encodeTime", suggestKey("encodtime", encoderConfigKeys), "wrong suggestion.")
+	assert.Equal(t, "maxSize", suggestKey("MAXSIZE2", lumberjackKeys), "wrong suggestion.")
+	assert.Equal(t, "", suggestKey("unrelated", lumberjackKeys), "no suggestion expected.")
+}
+
+func TestValidate(t *testing.T) {
+	problems := Validate(NewConfigOption(WithFileName("strict_config"), WithFileExt("yaml"), WithFilePaths(testFilePath)))
+	assert.Equal(t, 7, len(problems), "all problems should be reported.")
+	assert.Equal(t, "appender-file.encoderType", problems[0].Key, "the problems should be sorted by key.")
+	assert.IsType(t, &InvalidValueError{}, problems[0].Err)
+	assert.Equal(t, problems[0].Err.Error(), problems[0].Message)
+
+	// the sample config files are valid, the keys used as comments in JSON are allowed.
+	for _, file := range []string{"cfzap.yaml", "cfzap.json", filepath.Join(testFilePath, "cfzap.yaml"),
+		filepath.Join(testFilePath, "cfzap.json")} {
+		assert.Nil(t, Validate(NewConfigOption(WithFilePath(file))), "the sample config file should be valid: "+file)
+	}
+
+	// the item of appender list which is not an appender name is reported.
+	problems = Validate(NewConfigOption(WithData([]byte("appenders: [{name: appender-stdout}]"), "yaml")))
+	assert.Equal(t, 1, len(problems), "the invalid appender name should be reported.")
+	assert.Equal(t, "appenders[0]", problems[0].Key)
+
+	problems = Validate(NewConfigOption(WithFileName("no_file")))
+	assert.Equal(t, 1, len(problems), "the missing config file should be reported.")
+	assert.Equal(t, "", problems[0].Key, "the problem is not caused by a key.")
+}
+
+func TestValidateWithoutSideEffect(t *testing.T) {
+	// the directory is not created yet, it should not be created by validation.
+	dir := filepath.Join(t.TempDir(), "cfzap-validate-test")
+
+	data := []byte(`
+appenders: [appender-file]
+appender-file:
+  target: lumberjack
+  encoderConfig: encoderConfig
+lumberjack:
+  filename: ` + filepath.ToSlash(filepath.Join(dir, "test.log")) + `
+encoderConfig:
+  messageKey: MSG
+`)
+	assert.Nil(t, Validate(NewConfigOption(WithData(data, "yaml"))), "the config should be valid.")
+
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err), "no directory should be created.")
+}
+
+func TestLoadCoreStrictWithoutSideEffect(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	data := []byte(`
+appenders: [appender-file, appender-missing]
+appender-file:
+  target: lumberjack
+  encoderConfig: encoderConfig
+lumberjack:
+  filename: ` + filepath.ToSlash(filepath.Join(dir, "test.log")) + `
+encoderConfig:
+  messageKey: MSG
+`)
+	config, _, err := readConfigFile(NewConfigOption(WithData(data, "yaml")))
+	assert.Nil(t, err, "the config should be read in non strict mode.")
+
+	_, _, _, err = loadCore(config, "", NewNamedLevels(), nil, true)
+	var missing *MissingSectionError
+	assert.True(t, errors.As(err, &missing), "the missing appender should be reported in strict mode.")
+
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err), "no directory should be created for the rejected config.")
+}
@@ -0,0 +1,135 @@
+package cfzap
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDelay is the quiet period after the last file event before the change is reported.
+// editors usually write a file in several steps, we only want to reload once.
+const watchDelay = 100 * time.Millisecond
+
+// configWatcher watches config files and calls a function when any of them changed.
+type configWatcher struct {
+	watcher  *fsnotify.Watcher
+	onChange func()
+	// the cleaned file names being watched, mapped to their resolved real path.
+	files map[string]string
+	timer *time.Timer
+	done  chan struct{}
+	lock  sync.Mutex
+}
+
+// watchConfigFiles starts watching the given config files.
+// onChange is called in a separate goroutine after any of the files was modified or created.
+// it returns configWatcher object and error object.
+func watchConfigFiles(onChange func(), filenames ...string) (*configWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &configWatcher{
+		watcher:  watcher,
+		onChange: onChange,
+		files:    make(map[string]string),
+		done:     make(chan struct{}),
+	}
+
+	for _, filename := range filenames {
+		file := filepath.Clean(filename)
+		w.files[file], _ = filepath.EvalSymlinks(file)
+
+		// we have to watch the entire directory to pick up renames/atomic saves in a cross-platform way.
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+// run receives file events until the watcher is closed.
+func (w *configWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.isChanged(event) {
+				w.schedule()
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			defaultLogger.Warn("config watcher error: " + err.Error())
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// isChanged checks to see if the event means one of the watched files was changed.
+// besides writing and creating, the real path of the file may be changed, e.g. k8s ConfigMap replacement.
+func (w *configWatcher) isChanged(event fsnotify.Event) bool {
+	const writeOrCreateMask = fsnotify.Write | fsnotify.Create
+
+	changed := false
+	name := filepath.Clean(event.Name)
+
+	for file, realFile := range w.files {
+		currentFile, _ := filepath.EvalSymlinks(file)
+
+		if (name == file && event.Op&writeOrCreateMask != 0) || (currentFile != "" && currentFile != realFile) {
+			w.files[file] = currentFile
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// schedule calls onChange after watchDelay, the waiting restarts if another change comes.
+func (w *configWatcher) schedule() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+
+	w.timer = time.AfterFunc(watchDelay, func() {
+		select {
+		case <-w.done: // don't report any change after the watcher was closed.
+		default:
+			w.onChange()
+		}
+	})
+}
+
+// close stops watching. it is safe to call it more than once.
+func (w *configWatcher) close() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	select {
+	case <-w.done:
+		return
+	default:
+		close(w.done)
+	}
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+
+	_ = w.watcher.Close()
+}
@@ -0,0 +1,258 @@
+package cfzap
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+)
+
+// the keys of zap.Config, they are used to detect and convert the config of zap.
+var (
+	// the top level keys of zap.Config.
+	zapConfigKeys = []string{"level", "development", "disableCaller", "disableStacktrace", "sampling", "encoding",
+		"encoderConfig", "outputPaths", "errorOutputPaths", "initialFields"}
+	// the config without 'appenders' and 'loggers' is taken as zap.Config when any of these keys exists.
+	zapConfigMarkers = []string{"encoding", "outputPaths", "errorOutputPaths"}
+	// the keys of zapcore.EncoderConfig which have different names in encoderConfig section.
+	zapEncoderKeys = map[string]string{
+		"levelEncoder":    "encodeLevel",
+		"timeEncoder":     "encodeTime",
+		"durationEncoder": "encodeDuration",
+		"callerEncoder":   "encodeCaller",
+		"nameEncoder":     "encodeName",
+	}
+)
+
+// zapConverter converts the config of zap.Config into the format of cfzap.
+type zapConverter struct {
+	// the config of zap.Config.
+	config *viper.Viper
+	// the settings in the format of cfzap.
+	settings map[string]interface{}
+	// the appender names in order.
+	appenders []interface{}
+	// the targets used already, an output path used more than once is written by one appender.
+	targets map[string]bool
+}
+
+// isZapConfig checks to see if the config is a zap.Config document, such as:
+//
+//	{"level": "info", "encoding": "json", "outputPaths": ["stdout"], "encoderConfig": {"messageKey": "msg"}}
+func isZapConfig(config *viper.Viper) bool {
+	if config.IsSet("appenders") || config.IsSet(loggersSection) {
+		return false
+	}
+
+	for _, key := range zapConfigMarkers {
+		if config.IsSet(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// convertZapConfig converts the config of zap.Config into the format of cfzap, so it's loaded in the same way:
+//
+//   - each of 'outputPaths' becomes an appender, 'stdout' and 'stderr' are the targets of the same names,
+//     the files, including 'file://' URLs, become lumberjack sections using the default rolling policy.
+//   - each of 'errorOutputPaths' not in 'outputPaths' becomes an appender at 'error' level. cfzap has no
+//     output for the internal errors of zap, so these outputs receive the entries at 'error' level or above instead.
+//   - 'level', 'encoding' and 'encoderConfig' are used by all appenders.
+//   - 'development', 'disableCaller' and 'initialFields' become the options.
+//   - 'sampling' and 'disableStacktrace' are not supported, they are ignored with a warning.
+//
+// the unknown top level keys are ignored, they are found by checkZapConfig().
+// It returns the converted config object and nil when success, otherwise nil and error object.
+func convertZapConfig(config *viper.Viper) (*viper.Viper, error) {
+	if config.IsSet("sampling") {
+		defaultLogger.Warn("the key [sampling] of zap.Config is not supported, it's ignored")
+	}
+	if config.IsSet("disableStacktrace") && !config.GetBool("disableStacktrace") {
+		defaultLogger.Warn("the stacktrace is not added by cfzap, the key [disableStacktrace] of zap.Config is ignored")
+	}
+
+	c := &zapConverter{config: config, settings: make(map[string]interface{}), targets: make(map[string]bool)}
+
+	if !config.IsSet("outputPaths") {
+		return nil, &MissingKeyError{Key: "outputPaths"}
+	}
+
+	outputs := cast.ToStringSlice(config.Get("outputPaths"))
+	if len(outputs) == 0 {
+		return nil, &InvalidValueError{Key: "outputPaths", Value: config.Get("outputPaths"),
+			Reason: "no output path is defined"}
+	}
+
+	level := strings.TrimSpace(config.GetString("level"))
+	for _, output := range outputs {
+		if err := c.addAppender("outputPaths", output, "appender", level); err != nil {
+			return nil, err
+		}
+	}
+
+	errorLevel := zapErrorLevel(level)
+	for _, output := range cast.ToStringSlice(config.Get("errorOutputPaths")) {
+		if err := c.addAppender("errorOutputPaths", output, "appender-error", errorLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	c.settings["appenders"] = c.appenders
+	c.settings["encoderConfig"] = c.encoderConfig()
+	c.settings["options"] = c.options()
+
+	converted := viper.New()
+	if err := converted.MergeConfigMap(c.settings); err != nil {
+		return nil, err
+	}
+
+	return converted, nil
+}
+
+// checkZapConfig returns the unknown top level keys of zap.Config as *UnknownKeyError.
+func checkZapConfig(config *viper.Viper) []error {
+	var problems []error
+	for key := range config.AllSettings() {
+		if !containsFold(zapConfigKeys, key) && !isCommentKey(key) {
+			problems = append(problems, &UnknownKeyError{Key: key, Suggestion: suggestKey(key, zapConfigKeys)})
+		}
+	}
+
+	return problems
+}
+
+// addAppender adds the appender writing to output, key is the key listing output, such as 'outputPaths'.
+// the appender is named by prefix and the kind of target, such as 'appender-stdout' or 'appender-file-2'.
+// it's ignored if output is used already.
+func (c *zapConverter) addAppender(key string, output string, prefix string, level string) error {
+	output = strings.TrimSpace(output)
+
+	filename, err := zapOutputFile(output)
+	if err != nil {
+		return &InvalidValueError{Key: key, Value: output, Reason: err.Error()}
+	}
+
+	target := strings.ToLower(output)
+	if filename != "" {
+		target = filename
+	}
+	if c.targets[target] {
+		return nil
+	}
+	c.targets[target] = true
+
+	appender := map[string]interface{}{"encoderConfig": "encoderConfig"}
+	if filename == "" {
+		appender["target"] = target
+		prefix += "-" + target
+	} else {
+		section := uniqueKey(c.settings, "lumberjack")
+		c.settings[section] = map[string]interface{}{"filename": filename}
+		appender["target"] = section
+		prefix += "-file"
+	}
+
+	if encoding := strings.TrimSpace(c.config.GetString("encoding")); encoding != "" {
+		appender["encoderType"] = encoding
+	}
+	if level != "" {
+		appender["logLevel"] = level
+	}
+
+	name := uniqueKey(c.settings, prefix)
+	c.settings[name] = appender
+	c.appenders = append(c.appenders, name)
+
+	return nil
+}
+
+// encoderConfig returns the settings of encoderConfig section converted from zapcore.EncoderConfig.
+func (c *zapConverter) encoderConfig() map[string]interface{} {
+	settings := make(map[string]interface{})
+
+	values, _ := c.config.Get("encoderConfig").(map[string]interface{})
+	for key, value := range values {
+		name := key
+		for zapKey, cfzapKey := range zapEncoderKeys {
+			if strings.EqualFold(key, zapKey) {
+				name = cfzapKey
+			}
+		}
+		for _, cfzapKey := range encoderConfigKeys {
+			if strings.EqualFold(name, cfzapKey) {
+				name = cfzapKey
+			}
+		}
+
+		// the time encoder of zap can be a layout, such as {"layout": "2006-01-02"}.
+		if layout, ok := value.(map[string]interface{}); ok && name == "encodeTime" {
+			value = "%" + cast.ToString(layout["layout"])
+		}
+
+		settings[name] = value
+	}
+
+	if len(settings) == 0 {
+		// the section must exist, zap uses the same line ending when it's empty.
+		settings["lineEnding"] = zapcore.DefaultLineEnding
+	}
+
+	return settings
+}
+
+// options returns the settings of options section converted from zap.Config.
+func (c *zapConverter) options() map[string]interface{} {
+	options := map[string]interface{}{
+		// zap.Config adds the caller unless it's disabled.
+		"caller":      !c.config.GetBool("disableCaller"),
+		"development": c.config.GetBool("development"),
+	}
+
+	if fields, ok := c.config.Get("initialFields").(map[string]interface{}); ok && len(fields) > 0 {
+		options["fields"] = fields
+	}
+
+	return options
+}
+
+// zapOutputFile returns the file name of the output path of zap.Config, it's empty for 'stdout' and 'stderr'.
+// only the files, including 'file://' URLs, are supported besides 'stdout' and 'stderr'.
+func zapOutputFile(output string) (string, error) {
+	if strings.EqualFold(output, TargetStdout) || strings.EqualFold(output, TargetStderr) {
+		return "", nil
+	}
+
+	if output == "" {
+		return "", errors.New("the output path is empty")
+	}
+
+	// the Windows path such as 'C:\logs\app.log' is not an URL.
+	if strings.HasPrefix(strings.ToLower(output), "file://") {
+		u, err := url.Parse(output)
+		if err != nil {
+			return "", err
+		}
+
+		return u.Path, nil
+	}
+	if strings.Contains(output, "://") {
+		return "", errors.New("only stdout, stderr and files are supported")
+	}
+
+	return output, nil
+}
+
+// zapErrorLevel returns the level of the appenders of 'errorOutputPaths', it's 'error' unless level is higher.
+func zapErrorLevel(level string) string {
+	var l zapcore.Level
+	if err := l.UnmarshalText(toLowerBytes(level)); err == nil && l > zapcore.ErrorLevel {
+		return l.String()
+	}
+
+	return zapcore.ErrorLevel.String()
+}
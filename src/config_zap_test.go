@@ -0,0 +1,94 @@
+package cfzap
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestZapConfig(t *testing.T) {
+	option := NewConfigOption(WithFileName("zap_config"), WithFileExt("json"), WithFilePaths(testFilePath))
+
+	description, err := DescribeLogger(option, "")
+	assert.Nil(t, err, "the zap.Config should be converted.")
+	assert.Equal(t, OptionsDescription{Caller: true, Development: true, Fields: map[string]string{"app": "demo"}},
+		description.Options)
+
+	// 'stdout' in errorOutputPaths is written by the appender of outputPaths already.
+	assert.Equal(t, 3, len(description.Appenders))
+
+	stderr := description.Appenders[0]
+	assert.Equal(t, "appender-error-stderr", stderr.Name)
+	assert.Equal(t, TargetStderr, stderr.TargetKind)
+	assert.Equal(t, zapcore.ErrorLevel, stderr.Level)
+
+	file := description.Appenders[1]
+	assert.Equal(t, "appender-file", file.Name)
+	assert.Equal(t, TargetFile, file.TargetKind)
+	assert.Equal(t, "../logs/zap.log", file.File.Filename)
+	assert.Equal(t, zapcore.DebugLevel, file.Level)
+	assert.Equal(t, "console", file.EncoderType)
+	assert.Equal(t, EncoderDescription{
+		MessageKey:     "MSG",
+		LevelKey:       "LEVEL",
+		TimeKey:        "TIME",
+		EncodeLevel:    "capital",
+		EncodeTime:     "%2006-01-02 15:04:05",
+		EncodeDuration: "string",
+	}, file.Encoder)
+
+	stdout := description.Appenders[2]
+	assert.Equal(t, "appender-stdout", stdout.Name)
+	assert.Equal(t, TargetStdout, stdout.TargetKind)
+
+	// the same entry point creates the logger.
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	logger, err := r.GetLogger(option)
+	assert.Nil(t, err, "the logger should be created from zap.Config.")
+	logger.Info("created from zap.Config")
+}
+
+func TestZapConfigErrors(t *testing.T) {
+	data := []byte(`
+level: info
+encoding: json
+outputPaths: ["stdout"]
+outputPath: ["stderr"]
+encoderConfig:
+  messageKey: msg
+`)
+	_, err := DescribeLogger(NewConfigOption(WithData(data, "yaml")), "")
+	assert.Nil(t, err, "the unknown key is ignored in non strict mode.")
+
+	_, err = DescribeLogger(NewConfigOption(WithData(data, "yaml"), WithStrict(true)), "")
+	var unknown *UnknownKeyError
+	assert.True(t, errors.As(err, &unknown), "the unknown key should be reported in strict mode.")
+	assert.Equal(t, "outputPaths", unknown.Suggestion)
+
+	// Validate() reports it as strict mode does.
+	problems := Validate(NewConfigOption(WithData(data, "yaml")))
+	assert.Equal(t, 1, len(problems), "the unknown key should be reported by Validate().")
+	assert.Equal(t, "outputpath", problems[0].Key)
+	assert.IsType(t, &UnknownKeyError{}, problems[0].Err)
+
+	data = []byte(`{"encoding": "json", "errorOutputPaths": ["stderr"]}`)
+	_, err = DescribeLogger(NewConfigOption(WithData(data, "json")), "")
+	var missing *MissingKeyError
+	assert.True(t, errors.As(err, &missing), "outputPaths is required.")
+
+	data = []byte(`{"encoding": "json", "outputPaths": ["http://localhost/log"]}`)
+	_, err = DescribeLogger(NewConfigOption(WithData(data, "json")), "")
+	var invalid *InvalidValueError
+	assert.True(t, errors.As(err, &invalid), "only stdout, stderr and files are supported.")
+	assert.Equal(t, "outputPaths", invalid.Key)
+
+	data = []byte(`{"encoding": "json", "outputPaths": ["file:///var/log/app.log"]}`)
+	description, err := DescribeLogger(NewConfigOption(WithData(data, "json")), "")
+	assert.Nil(t, err, "the file URL should be supported.")
+	assert.Equal(t, "/var/log/app.log", description.Appenders[0].File.Filename)
+}
@@ -0,0 +1,207 @@
+package cfzap
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NamedLevels holds the levels of loggers by their names, as produced by zap.Logger.Named(), such as 'db.pool'.
+// The level of a logger is decided by the longest matching name, so 'db' applies to 'db.pool' too,
+// unless 'db.pool' has its own level. The level replaces the level of the appenders for matched loggers,
+// but never the threshold of the appenders.
+// It is safe to change the levels at runtime.
+type NamedLevels struct {
+	levels map[string]zapcore.Level
+	lock   sync.RWMutex
+}
+
+// NewNamedLevels creates and returns an empty NamedLevels object.
+func NewNamedLevels() *NamedLevels {
+	return &NamedLevels{levels: make(map[string]zapcore.Level)}
+}
+
+// NamedLevelsOf returns the NamedLevels used by the logger.
+// It returns false if the logger is not created by this package.
+func NamedLevelsOf(logger *zap.Logger) (*NamedLevels, bool) {
+	if holder, ok := holderOf(logger); ok && holder.levels != nil {
+		return holder.levels, true
+	}
+
+	return nil, false
+}
+
+// SetLevel sets the level of the logger name and all its children.
+func (l *NamedLevels) SetLevel(name string, level zapcore.Level) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.levels[name] = level
+}
+
+// RemoveLevel removes the level of the logger name, it uses the level of its parent or appenders after that.
+func (l *NamedLevels) RemoveLevel(name string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.levels, name)
+}
+
+// Level returns the level of the logger name by longest matching.
+// It returns false if no name matches.
+func (l *NamedLevels) Level(name string) (zapcore.Level, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	for {
+		if level, ok := l.levels[name]; ok {
+			return level, true
+		}
+
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return zapcore.InfoLevel, false
+		}
+		name = name[:i]
+	}
+}
+
+// Levels returns a copy of all levels set.
+func (l *NamedLevels) Levels() map[string]zapcore.Level {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	levels := make(map[string]zapcore.Level, len(l.levels))
+	for k, v := range l.levels {
+		levels[k] = v
+	}
+
+	return levels
+}
+
+// reset replaces all levels with the given ones.
+func (l *NamedLevels) reset(levels map[string]zapcore.Level) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.levels = make(map[string]zapcore.Level, len(levels))
+	for k, v := range levels {
+		l.levels[k] = v
+	}
+}
+
+// enabled checks to see if any of the levels enables the given level.
+func (l *NamedLevels) enabled(level zapcore.Level) bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	for _, v := range l.levels {
+		if v.Enabled(level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// namedLevelCore wraps the core of an appender.
+// it writes an entry when the entry level is enabled by the level of its logger name,
+// or by the level of the appender when no name matches, and never below the threshold of the appender.
+type namedLevelCore struct {
+	// the core of the appender, it should enable all levels.
+	core zapcore.Core
+	// the level of the appender.
+	level zapcore.LevelEnabler
+	// the lowest level written whatever the levels by logger names.
+	threshold zapcore.Level
+	// the levels by logger names.
+	levels *NamedLevels
+	// the entries being written by the core, it can be nil.
+	usage *coreUsage
+	// the core added to CheckedEntry when usage is not nil, it finishes the entry after writing.
+	counted *countedCore
+}
+
+// countedCore writes the entries checked by namedLevelCore, and finishes them in its usage.
+type countedCore struct {
+	*namedLevelCore
+}
+
+// Write implements zapcore.Core.
+func (c *countedCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	defer c.usage.release()
+
+	return c.core.Write(entry, fields)
+}
+
+// newNamedLevelCore creates the core of an appender which respects the levels by logger names.
+// usage counts the entries checked until they are written, it can be nil.
+func newNamedLevelCore(encoder zapcore.Encoder, writeSyncer zapcore.WriteSyncer, level zapcore.LevelEnabler,
+	threshold zapcore.Level, levels *NamedLevels, usage *coreUsage) zapcore.Core {
+	// the level is checked by namedLevelCore, so the inner core accepts all levels.
+	allLevels := zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })
+
+	return withUsage(&namedLevelCore{
+		core:      zapcore.NewCore(encoder, writeSyncer, allLevels),
+		level:     level,
+		threshold: threshold,
+		levels:    levels,
+		usage:     usage,
+	})
+}
+
+// withUsage sets up the core added to CheckedEntry, and returns c.
+func withUsage(c *namedLevelCore) *namedLevelCore {
+	if c.usage != nil {
+		c.counted = &countedCore{namedLevelCore: c}
+	}
+
+	return c
+}
+
+// Enabled implements zapcore.LevelEnabler.
+// the level is enabled if it may be enabled by any logger name.
+func (c *namedLevelCore) Enabled(level zapcore.Level) bool {
+	return c.threshold.Enabled(level) && (c.level.Enabled(level) || c.levels.enabled(level))
+}
+
+// With implements zapcore.Core.
+func (c *namedLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return withUsage(&namedLevelCore{core: c.core.With(fields), level: c.level, threshold: c.threshold,
+		levels: c.levels, usage: c.usage})
+}
+
+// Check implements zapcore.Core.
+func (c *namedLevelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.threshold.Enabled(entry.Level) {
+		return checked
+	}
+
+	level, ok := c.levels.Level(entry.LoggerName)
+	if ok && !level.Enabled(entry.Level) {
+		return checked
+	}
+	if !ok && !c.level.Enabled(entry.Level) {
+		return checked
+	}
+
+	// the entry is counted until it's written, so the appender is not closed before that.
+	if c.usage != nil {
+		c.usage.acquire()
+		return checked.AddCore(entry, c.counted)
+	}
+
+	return checked.AddCore(entry, c)
+}
+
+// Write implements zapcore.Core.
+func (c *namedLevelCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.core.Write(entry, fields)
+}
+
+// Sync implements zapcore.Core.
+func (c *namedLevelCore) Sync() error {
+	return c.core.Sync()
+}
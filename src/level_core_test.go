@@ -0,0 +1,82 @@
+package cfzap
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNamedLevels(t *testing.T) {
+	levels := NewNamedLevels()
+	levels.SetLevel("db", zapcore.WarnLevel)
+	levels.SetLevel("db.pool", zapcore.DebugLevel)
+
+	level, ok := levels.Level("db.pool.conn")
+	assert.True(t, ok, "db.pool.conn should match db.pool")
+	assert.Equal(t, zapcore.DebugLevel, level, "the longest matching name should be used")
+
+	level, ok = levels.Level("db.cache")
+	assert.True(t, ok, "db.cache should match db")
+	assert.Equal(t, zapcore.WarnLevel, level, "the level of db should be used")
+
+	_, ok = levels.Level("dbx")
+	assert.False(t, ok, "dbx should not match db")
+	_, ok = levels.Level("")
+	assert.False(t, ok, "empty name should not match any name")
+
+	levels.RemoveLevel("db.pool")
+	level, _ = levels.Level("db.pool")
+	assert.Equal(t, zapcore.WarnLevel, level, "the level of parent should be used after removing")
+	assert.Equal(t, 1, len(levels.Levels()), "there should be only one level")
+}
+
+func TestNamedLevelCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+
+	levels := NewNamedLevels()
+	levels.SetLevel("db.pool", zapcore.DebugLevel)
+	levels.SetLevel("http", zapcore.WarnLevel)
+
+	core := newNamedLevelCore(encoder, zapcore.AddSync(io.Discard), zapcore.InfoLevel, zapcore.DebugLevel, levels, nil)
+	// replace the inner core to observe the written entries.
+	core.(*namedLevelCore).core = observed
+	logger := zap.New(core)
+	assert.True(t, logger.Core().Enabled(zapcore.DebugLevel), "debug may be enabled by db.pool")
+
+	logger.Debug("appender level is info")
+	logger.Info("appender level is info")
+	logger.Named("db").Named("pool").Debug("db.pool level is debug")
+	logger.Named("http").Info("http level is warn")
+	logger.Named("http").Warn("http level is warn")
+	assert.Equal(t, 3, logs.Len(), "3 entries should be written")
+
+	// change the level at runtime.
+	levels.SetLevel("http", zapcore.DebugLevel)
+	logger.Named("http").Debug("http level is debug now")
+	assert.Equal(t, 4, logs.Len(), "the changed level should be used")
+}
+
+func TestNamedLevelCoreThreshold(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+
+	levels := NewNamedLevels()
+	levels.SetLevel("db.pool", zapcore.DebugLevel)
+
+	// an error-only appender, such as the one with a threshold filter imported from log4j.
+	core := newNamedLevelCore(encoder, zapcore.AddSync(io.Discard), zapcore.ErrorLevel, zapcore.ErrorLevel, levels, nil)
+	core.(*namedLevelCore).core = observed
+	logger := zap.New(core).Named("db").Named("pool")
+
+	assert.False(t, logger.Core().Enabled(zapcore.DebugLevel), "debug should be dropped by the threshold")
+	logger.Debug("threshold is error")
+	logger.Warn("threshold is error")
+	logger.Error("threshold is error")
+	assert.Equal(t, 1, logs.Len(), "only the error entry should be written")
+	assert.Equal(t, zapcore.ErrorLevel, logs.All()[0].Level)
+}
@@ -0,0 +1,136 @@
+package cfzap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelHandler is an http.Handler which reports and changes the levels of the appenders used by a logger.
+type levelHandler struct {
+	holder *coreHolder
+}
+
+// appenderLevel is the JSON payload of an appender level.
+// it's compatible with the payload of zap.AtomicLevel.ServeHTTP(), with an additional appender name.
+type appenderLevel struct {
+	Appender string        `json:"appender"`
+	Level    zapcore.Level `json:"level"`
+}
+
+// appenderLevels is the JSON payload of all appender levels.
+type appenderLevels struct {
+	Appenders []appenderLevel `json:"appenders"`
+}
+
+// errorResponse is the JSON payload of an error, it's same as zap.AtomicLevel.ServeHTTP().
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewLevelHandler returns an http.Handler which reports and changes the levels of the appenders used by the logger.
+// The handler always works on the appenders currently used, even after the logger is created again or reloaded.
+// Note, the levels changed by the handler are discarded when the logger is created again or reloaded.
+//
+// The appender is chosen by the query parameter 'appender', the request is served in the same way
+// as zap.AtomicLevel.ServeHTTP() for that appender:
+//
+//	curl localhost:8080/log/level?appender=appender-file
+//	curl -X PUT localhost:8080/log/level?appender=appender-file -d level=debug
+//
+// Without the query parameter, GET returns the levels of all appenders like:
+//
+//	{"appenders":[{"appender":"appender-file","level":"debug"},{"appender":"appender-stdout","level":"info"}]}
+//
+// and PUT changes the levels of all appenders, the request body is same as zap.AtomicLevel.ServeHTTP().
+//
+// It returns error if the logger is not created by this package.
+func NewLevelHandler(logger *zap.Logger) (http.Handler, error) {
+	holder, ok := holderOf(logger)
+	if !ok {
+		return nil, errors.New("the logger is not created by cfzap")
+	}
+
+	return &levelHandler{holder: holder}, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	appenders := h.holder.loadAppenders()
+	enc := json.NewEncoder(w)
+
+	if name := r.URL.Query().Get("appender"); name != "" {
+		appender, ok := appenders[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = enc.Encode(errorResponse{Error: fmt.Sprintf("appender [%s] is not found", name)})
+			return
+		}
+
+		// the single appender is served by zap directly.
+		appender.logLevel.ServeHTTP(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		level, err := decodeLevel(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorResponse{Error: err.Error()})
+			return
+		}
+
+		for _, appender := range appenders {
+			appender.logLevel.SetLevel(level)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = enc.Encode(errorResponse{Error: "Only GET and PUT are supported."})
+		return
+	}
+
+	payload := appenderLevels{Appenders: make([]appenderLevel, 0, len(appenders))}
+	for name, appender := range appenders {
+		payload.Appenders = append(payload.Appenders, appenderLevel{Appender: name, Level: appender.logLevel.Level()})
+	}
+	sort.Slice(payload.Appenders, func(i, j int) bool {
+		return payload.Appenders[i].Appender < payload.Appenders[j].Appender
+	})
+
+	_ = enc.Encode(payload)
+}
+
+// decodeLevel decodes the level from PUT request in the same way as zap.AtomicLevel.ServeHTTP().
+// the level is URL encoded for 'application/x-www-form-urlencoded', otherwise it's JSON encoded.
+func decodeLevel(r *http.Request) (zapcore.Level, error) {
+	var level zapcore.Level
+
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		s := r.FormValue("level")
+		if s == "" {
+			return level, errors.New("must specify logging level")
+		}
+
+		err := level.UnmarshalText([]byte(s))
+		return level, err
+	}
+
+	var payload struct {
+		Level *zapcore.Level `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return level, fmt.Errorf("malformed request body: %v", err)
+	}
+	if payload.Level == nil {
+		return level, errors.New("must specify logging level")
+	}
+
+	return *payload.Level, nil
+}
@@ -0,0 +1,65 @@
+package cfzap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestLevelHandler(t *testing.T) {
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	logger, err := r.GetLogger(NewConfigOption(WithFileName("logger_config"), WithFileExt("yaml"), WithFilePaths(testFilePath)))
+	assert.Nil(t, err, "fail to create a new logger from logger_config.yaml.")
+
+	_, err = NewLevelHandler(zap.NewNop())
+	assert.NotNil(t, err, "the logger not created by cfzap should not be served.")
+
+	handler, err := NewLevelHandler(logger)
+	assert.Nil(t, err, "fail to create level handler.")
+
+	serve := func(method string, target string, body string) (int, string) {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if method == http.MethodPut && !strings.HasPrefix(body, "{") {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		return recorder.Code, strings.TrimSpace(recorder.Body.String())
+	}
+
+	code, body := serve(http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"appenders":[{"appender":"appender-stdout","level":"debug"}]}`, body)
+
+	code, body = serve(http.MethodGet, "/?appender=appender-stdout", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"level":"debug"}`, body)
+
+	code, body = serve(http.MethodPut, "/?appender=appender-stdout", "level=warn")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"level":"warn"}`, body)
+	assert.False(t, logger.Core().Enabled(zap.InfoLevel), "info level should be disabled.")
+
+	code, body = serve(http.MethodPut, "/", `{"level":"info"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"appenders":[{"appender":"appender-stdout","level":"info"}]}`, body)
+	assert.True(t, logger.Core().Enabled(zap.InfoLevel), "info level should be enabled.")
+
+	code, _ = serve(http.MethodPut, "/", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = serve(http.MethodGet, "/?appender=unknown", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = serve(http.MethodPost, "/", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -1,21 +1,23 @@
 package cfzap
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 var (
-	// the logger that should be created according to configuration.
-	logger *zap.Logger
-
 	// default logger in case failed to create logger from config file.
 	defaultLogger *zap.Logger
 
-	// the ConfigOption was used last time. Note, it is not a pointer.
-	lastConfigOption ConfigOption
+	// the registry used by the package level functions, such as GetLogger().
+	defaultRegistry = NewRegistry()
 
 	lock sync.Mutex
 )
@@ -36,70 +38,141 @@ func init() {
 	}
 }
 
-// GetLogger returns a logger according to the config file.
-// If 'createNew' is true, then trying to return the exist logger created before.
+// GetLogger returns a logger according to the config file, the logger is cached by configOption in the default registry.
+// If 'createNew' is false, then trying to return the exist logger created from the same ConfigOption before.
+// When the logger is created again, the loggers returned before, including their children created by With() or Named(),
+// write to the new appenders too. Only their options, such as 'caller', are not changed.
+// The logger is kept in the default registry until RemoveLogger() or Shutdown() is called.
 // In theory, even with an error, the returned logger will not be nil.
+// If only some appenders failed to load, the logger using the others is returned with *ConfigErrors listing the failures,
+// and the same failures are returned whenever the cached logger is returned.
 func GetLogger(configOption *ConfigOption) (*zap.Logger, error) {
-	lock.Lock()
-	defer lock.Unlock()
+	return defaultRegistry.GetLogger(configOption)
+}
 
-	if configOption == nil { // using default value if it is not provided.
-		configOption = NewConfigOption()
-	}
+// GetNamedLogger returns the logger named loggerName in the 'loggers' section of the config file,
+// the logger is cached by configOption and loggerName in the default registry.
+// See Registry.GetNamedLogger() for details.
+func GetNamedLogger(configOption *ConfigOption, loggerName string) (*zap.Logger, error) {
+	return defaultRegistry.GetNamedLogger(configOption, loggerName)
+}
 
-	if !shouldCreateNewLogger(configOption) {
-		return logger, nil
+// NewLoggerFromViper creates a logger from the sub-tree of config under key, such as 'logging'.
+// The logger is cached by config and key in the default registry. See Registry.NewLoggerFromViper() for details.
+func NewLoggerFromViper(config *viper.Viper, key string) (*zap.Logger, error) {
+	return defaultRegistry.NewLoggerFromViper(config, key)
+}
+
+// Register creates a logger according to the config file, and caches it by name in the default registry.
+// See Registry.Register() for details.
+func Register(name string, configOption *ConfigOption) (*zap.Logger, error) {
+	return defaultRegistry.Register(name, configOption)
+}
+
+// Get returns the logger registered by name in the default registry.
+// It returns false if there's no such logger, or the logger has been closed by Shutdown().
+func Get(name string) (*zap.Logger, bool) {
+	return defaultRegistry.Get(name)
+}
+
+// Remove flushes and closes the logger registered by name, then removes it from the default registry.
+// See Registry.Remove() for details.
+func Remove(name string) error {
+	return defaultRegistry.Remove(name)
+}
+
+// RemoveLogger flushes and closes the appenders of the logger created by GetLogger(), GetNamedLogger()
+// or NewLoggerFromViper(), then removes it from the default registry.
+// See Registry.RemoveLogger() for details.
+func RemoveLogger(logger *zap.Logger) error {
+	return defaultRegistry.RemoveLogger(logger)
+}
+
+// Shutdown flushes and closes all appenders used by the loggers in the default registry,
+// and stops watching the config files.
+// See Registry.Shutdown() for details.
+func Shutdown(ctx context.Context) error {
+	return defaultRegistry.Shutdown(ctx)
+}
+
+// DumpEffectiveConfig serializes the config actually used by the loggers in the default registry.
+// See Registry.DumpEffectiveConfig() for details.
+func DumpEffectiveConfig(format string) ([]byte, error) {
+	return defaultRegistry.DumpEffectiveConfig(format)
+}
+
+// loadCore loads all appenders of the logger from config and combines them to one zapcore.Core.
+// the top level logger is used when loggerName is empty.
+// the core respects the levels by logger names, besides the level of each appender.
+// the appenders failed to load are reported by defaultLogger and returned as failures,
+// no core is created if any of them failed in strict mode, and no writer is built if any of them fails to resolve.
+// usage counts the entries being written by the core, it can be nil.
+// it returns the core, the appenders used by the core, the failures and error object.
+func loadCore(config *viper.Viper, loggerName string, levels *NamedLevels, usage *coreUsage,
+	strict bool) (zapcore.Core, map[string]*appenderConfig, []error, error) {
+	// the appenders are resolved before any writer is built, so a rejected config creates no directory.
+	if strict {
+		_, errors, err := describeLoggerAppenders(config, loggerName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(errors) > 0 {
+			failures := make([]error, 0, len(errors))
+			for _, v := range errors {
+				failures = append(failures, v)
+			}
+			return nil, nil, nil, newConfigErrors(failures)
+		}
 	}
 
-	config, err := readConfigFile(configOption)
+	appenders, errors, err := loadLoggerAppenders(config, loggerName)
 	if err != nil {
-		defaultLogger.Warn("fail to load logger config: " + err.Error())
-		return defaultLogger, err
+		return nil, nil, nil, err
 	}
 
-	appenders, errors, err := loadAppenders(config)
-
-	if err != nil {
+	failures := make([]error, 0, len(errors))
+	for _, v := range errors {
+		defaultLogger.Warn(v.Error())
 		_ = defaultLogger.Sync()
-		return defaultLogger, err
+		failures = append(failures, v)
+	}
+
+	// no half-configured logger is created in strict mode.
+	if strict && len(failures) > 0 {
+		_ = closeAppenders(appenders)
+		return nil, nil, nil, newConfigErrors(failures)
 	}
 
 	cores := make([]zapcore.Core, len(appenders))
 	i := 0
 	for _, appender := range appenders {
-		cores[i] = zapcore.NewCore(*appender.encoder, *appender.writeSyncer, appender.logLevel)
+		cores[i] = newNamedLevelCore(*appender.encoder, *appender.writeSyncer, appender.logLevel,
+			appender.description.Threshold, levels, usage)
 		i++
 	}
 
-	for k, v := range errors {
-		defaultLogger.Warn("fail to load appender [" + k + "]: " + v.Error())
-		_ = defaultLogger.Sync()
-	}
-
-	if logger != nil { // flush old logger before creating a new one.
-		_ = logger.Sync()
-	}
-
-	// clone and save the new configOption.
-	lastConfigOption = *configOption
-
-	// create a new logger.
-	options := loadLogOptions(config)
-	core := zapcore.NewTee(cores...)
-	logger = zap.New(core, options...)
+	return zapcore.NewTee(cores...), appenders, failures, nil
+}
 
-	return logger, nil
+// ShutdownError is returned when some of the appenders failed to be flushed or closed.
+type ShutdownError struct {
+	// Errors maps the appender name to its error.
+	// When more than one logger is closed, the name is prefixed by the logger name, as 'logger/appender'.
+	Errors map[string]error
 }
 
-// shouldCreateNewLogger check to see if we should create a new logger object.
-func shouldCreateNewLogger(configOption *ConfigOption) bool {
-	if logger == nil { // we don't have a created logger yet.
-		return true
+// Error implements error interface.
+func (e *ShutdownError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
 	}
-	if configOption.CreateNew { // the option does require create a new one.
-		return true
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = "[" + name + "]: " + e.Errors[name].Error()
 	}
 
-	// we have to create a new one when the new option is different compare to the last one.
-	return !configOption.equal(&lastConfigOption)
+	return fmt.Sprintf("fail to close %d appenders: %s", len(names), strings.Join(messages, "; "))
 }
@@ -0,0 +1,197 @@
+package cfzap
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// coreHolder holds the zapcore.Core currently used by a logger, it can be replaced at any time.
+// it also holds the state shared by all cores it has held.
+type coreHolder struct {
+	// the value is always a coreBox, atomic.Value requires a consistent concrete type.
+	value atomic.Value
+	// the levels by logger names used by the cores.
+	levels *NamedLevels
+	// the appenders used by the current core, the value is always a map[string]*appenderConfig.
+	appenders atomic.Value
+	// the config files loaded to create the current core, the value is always a []ConfigSource.
+	sources atomic.Value
+}
+
+// coreBox wraps a zapcore.Core to store it in atomic.Value.
+type coreBox struct {
+	core zapcore.Core
+	// generation increases every time the core is replaced.
+	generation uint64
+	// the entries being written by the core, it's nil if they are not counted.
+	usage *coreUsage
+}
+
+// newCoreHolder creates and returns coreHolder object using the given core.
+// usage counts the entries being written by the core, it can be nil.
+func newCoreHolder(core zapcore.Core, usage *coreUsage, levels *NamedLevels) *coreHolder {
+	holder := &coreHolder{levels: levels}
+	holder.value.Store(coreBox{core: core, usage: usage})
+	holder.appenders.Store(map[string]*appenderConfig(nil))
+	holder.sources.Store([]ConfigSource(nil))
+
+	return holder
+}
+
+// holderOf returns the coreHolder used by the logger.
+// it returns false if the logger is not created by this package.
+func holderOf(logger *zap.Logger) (*coreHolder, bool) {
+	if core, ok := logger.Core().(*swapCore); ok {
+		return core.holder, true
+	}
+
+	return nil, false
+}
+
+// load returns the current core and its generation.
+func (holder *coreHolder) load() coreBox {
+	return holder.value.Load().(coreBox)
+}
+
+// loadAppenders returns the appenders used by the current core.
+func (holder *coreHolder) loadAppenders() map[string]*appenderConfig {
+	return holder.appenders.Load().(map[string]*appenderConfig)
+}
+
+// loadSources returns the config files loaded to create the current core.
+func (holder *coreHolder) loadSources() []ConfigSource {
+	return holder.sources.Load().([]ConfigSource)
+}
+
+// swap replaces the current core and returns the old one.
+// usage counts the entries being written by the new core, it can be nil.
+// it's not safe to call it concurrently, the caller should hold the package lock.
+func (holder *coreHolder) swap(core zapcore.Core, usage *coreUsage) zapcore.Core {
+	old := holder.load()
+	holder.value.Store(coreBox{core: core, generation: old.generation + 1, usage: usage})
+
+	return old.core
+}
+
+// swapCore is a zapcore.Core which delegates all calls to the core in a coreHolder.
+// all loggers derived from the same coreHolder, including children created by With() or Named(),
+// start writing to the new core as soon as it is swapped.
+type swapCore struct {
+	holder *coreHolder
+	// the fields added by With(), they are applied to the current core when needed.
+	fields []zapcore.Field
+	// the current core with fields applied, it's a coreBox and cached by generation.
+	cache atomic.Value
+}
+
+// newSwapCore creates and returns swapCore object delegating to the given holder.
+func newSwapCore(holder *coreHolder) *swapCore {
+	return &swapCore{holder: holder}
+}
+
+// current returns the core in holder with the fields applied.
+func (c *swapCore) current() zapcore.Core {
+	return c.coreOf(c.holder.load())
+}
+
+// coreOf returns the core in box with the fields applied.
+func (c *swapCore) coreOf(box coreBox) zapcore.Core {
+	if len(c.fields) == 0 {
+		return box.core
+	}
+
+	if cached, ok := c.cache.Load().(coreBox); ok && cached.generation == box.generation {
+		return cached.core
+	}
+
+	box.core = box.core.With(c.fields)
+	c.cache.Store(box)
+
+	return box.core
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (c *swapCore) Enabled(level zapcore.Level) bool {
+	return c.current().Enabled(level)
+}
+
+// With implements zapcore.Core.
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+
+	return &swapCore{holder: c.holder, fields: all}
+}
+
+// Check implements zapcore.Core.
+// the current core adds itself to the CheckedEntry, so the entry is written to the core
+// which was in use when it was checked. the core is in use while it's checking, so it's not
+// retired in the middle, and the cores added count the entry until it's written.
+func (c *swapCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	for {
+		box := c.holder.load()
+		if box.usage == nil {
+			return c.coreOf(box).Check(entry, checked)
+		}
+
+		box.usage.acquire()
+		// the core may have been replaced and retired before it's acquired, then the new one is used.
+		if c.holder.load().generation != box.generation {
+			box.usage.release()
+			continue
+		}
+
+		checked = c.coreOf(box).Check(entry, checked)
+		box.usage.release()
+
+		return checked
+	}
+}
+
+// Write implements zapcore.Core.
+func (c *swapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(entry, fields)
+}
+
+// Sync implements zapcore.Core.
+func (c *swapCore) Sync() error {
+	return c.holder.load().core.Sync()
+}
+
+// coreUsage counts the entries being checked or written by a core, so the appenders of the core
+// are closed only after the core is retired and no entry is being written.
+type coreUsage struct {
+	// the number of the entries in progress.
+	count int64
+	// retired is 1 after retire() is called.
+	retired int32
+	// called once when the core is retired and no entry is in progress.
+	onIdle func()
+	once   sync.Once
+}
+
+// acquire counts an entry in progress.
+func (u *coreUsage) acquire() {
+	atomic.AddInt64(&u.count, 1)
+}
+
+// release finishes an entry in progress, onIdle is called if it's the last one of the retired core.
+func (u *coreUsage) release() {
+	if atomic.AddInt64(&u.count, -1) == 0 && atomic.LoadInt32(&u.retired) == 1 {
+		u.once.Do(u.onIdle)
+	}
+}
+
+// retire marks the core as no longer used by new entries, onIdle is called when no entry is in progress.
+// it must be called only once, after the core is replaced.
+func (u *coreUsage) retire(onIdle func()) {
+	u.onIdle = onIdle
+	atomic.StoreInt32(&u.retired, 1)
+	if atomic.LoadInt64(&u.count) == 0 {
+		u.once.Do(u.onIdle)
+	}
+}
@@ -0,0 +1,33 @@
+package cfzap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSwapCore(t *testing.T) {
+	core1, logs1 := observer.New(zap.InfoLevel)
+	core2, logs2 := observer.New(zap.DebugLevel)
+
+	holder := newCoreHolder(core1, nil, nil)
+	logger := zap.New(newSwapCore(holder))
+	child := logger.With(zap.String("a", "b")).Named("child")
+
+	child.Debug("not enabled")
+	child.Info("write to core1")
+	assert.Equal(t, 1, logs1.Len(), "only info message should be written to core1.")
+
+	old := holder.swap(core2, nil)
+	assert.Equal(t, core1, old, "swap should return the old core.")
+
+	child.Debug("write to core2")
+	assert.Equal(t, 1, logs1.Len(), "nothing more should be written to core1.")
+	assert.Equal(t, 1, logs2.Len(), "debug message should be written to core2.")
+
+	entry := logs2.All()[0]
+	assert.Equal(t, "child", entry.LoggerName, "logger name should be kept.")
+	assert.Equal(t, "b", entry.ContextMap()["a"], "fields added by With() should be kept.")
+}
@@ -0,0 +1,222 @@
+package cfzap
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+)
+
+// The kinds of appender targets.
+const (
+	// TargetStdout means the appender writes to os.Stdout.
+	TargetStdout = "stdout"
+	// TargetStderr means the appender writes to os.Stderr.
+	TargetStderr = "stderr"
+	// TargetFile means the appender writes to a rolling file described by a lumberjack section.
+	TargetFile = "file"
+)
+
+// LoggerDescription describes a logger resolved from config, see DescribeLogger().
+type LoggerDescription struct {
+	// Name is the logger name in 'loggers' section, it's empty for the top level logger.
+	Name string
+	// Appenders are the appenders loaded successfully, sorted by their names.
+	Appenders []AppenderDescription
+	// Options are the options of zap.Logger.
+	Options OptionsDescription
+	// Levels are the levels by logger names, such as 'db.pool'.
+	Levels map[string]zapcore.Level
+	// Sources are the config files the logger is resolved from.
+	Sources []ConfigSource
+}
+
+// AppenderDescription describes an appender resolved from config.
+type AppenderDescription struct {
+	// Name is the name of the appender section.
+	Name string
+	// Target is the value of 'target', such as 'stdout' or the name of a lumberjack section.
+	Target string
+	// TargetKind is one of TargetStdout, TargetStderr and TargetFile.
+	TargetKind string
+	// File describes the log file, it's nil unless TargetKind is TargetFile.
+	File *FileDescription
+	// Level is the level of the appender.
+	Level zapcore.Level
+	// Threshold is the lowest level written whatever the levels by logger names, it's DebugLevel if not set.
+	Threshold zapcore.Level
+	// EncoderType is 'json' or 'console'.
+	EncoderType string
+	// EncoderConfig is the name of the encoderConfig section.
+	EncoderConfig string
+	// Encoder describes the encoderConfig section.
+	Encoder EncoderDescription
+}
+
+// FileDescription describes the log file of an appender, as the lumberjack section.
+type FileDescription struct {
+	// Filename is the normalized path of the log file, it uses '/' as separator.
+	Filename   string
+	MaxSize    int
+	MaxAge     int
+	MaxBackups int
+	LocalTime  bool
+	Compress   bool
+}
+
+// EncoderDescription describes the encoderConfig section, the keys are same as zapcore.EncoderConfig.
+// The encoders are the values in config, such as 'capital' for EncodeLevel, empty if not set.
+type EncoderDescription struct {
+	MessageKey       string
+	LevelKey         string
+	TimeKey          string
+	NameKey          string
+	CallerKey        string
+	FunctionKey      string
+	StacktraceKey    string
+	LineEnding       string
+	ConsoleSeparator string
+	EncodeLevel      string
+	EncodeTime       string
+	EncodeDuration   string
+	EncodeCaller     string
+	EncodeName       string
+}
+
+// OptionsDescription describes the options of zap.Logger.
+type OptionsDescription struct {
+	// Caller is true if zap.AddCaller() is used.
+	Caller bool
+	// Development is true if zap.Development() is used.
+	Development bool
+	// Fields are the fields added to all entries.
+	Fields map[string]string
+}
+
+// DescribeLogger resolves the logger named loggerName in the 'loggers' section of the config file
+// without creating it, the top level logger is used when loggerName is empty.
+// It's a dry run of GetNamedLogger(): no writer is opened, no directory is created and no logger is registered.
+// The appenders failed to resolve are returned as *ConfigErrors along with the description,
+// or as the error without description in strict mode. If configOption is nil, the default ConfigOption is used.
+func DescribeLogger(configOption *ConfigOption, loggerName string) (*LoggerDescription, error) {
+	if configOption == nil { // using default value if it is not provided.
+		configOption = NewConfigOption()
+	}
+
+	config, sources, err := readConfigFile(configOption)
+	if err != nil {
+		return nil, err
+	}
+
+	description, failures, err := describeLogger(config, sources, loggerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if configOption.Strict && len(failures) > 0 {
+		return nil, newConfigErrors(failures)
+	}
+
+	return description, newConfigErrors(failures)
+}
+
+// DescribeLoggers resolves all loggers defined in the config file as DescribeLogger(): the top level logger first
+// if the top level 'appenders' exists, then the loggers in 'loggers' section sorted by name.
+// The config file is read only once, so it works with io.Reader too.
+// The appenders failed to resolve, and the loggers none of whose appenders is resolved, are returned as
+// *ConfigErrors along with the descriptions of the other loggers, or as the error without description in strict mode.
+func DescribeLoggers(configOption *ConfigOption) ([]*LoggerDescription, error) {
+	if configOption == nil { // using default value if it is not provided.
+		configOption = NewConfigOption()
+	}
+
+	config, sources, err := readConfigFile(configOption)
+	if err != nil {
+		return nil, err
+	}
+
+	names := loggerNames(config)
+	if len(names) == 0 {
+		return nil, &MissingSectionError{Key: "appenders"}
+	}
+
+	var descriptions []*LoggerDescription
+	var failures []error
+	seen := make(map[string]bool)
+	// the appenders shared by loggers are reported once.
+	addFailure := func(err error) {
+		if !seen[err.Error()] {
+			seen[err.Error()] = true
+			failures = append(failures, err)
+		}
+	}
+
+	for _, name := range names {
+		description, errs, err := describeLogger(config, sources, name)
+		if err != nil {
+			var configErrors *ConfigErrors
+			if errors.As(err, &configErrors) {
+				errs = configErrors.Errors
+			} else {
+				errs = []error{err}
+			}
+		} else {
+			descriptions = append(descriptions, description)
+		}
+
+		for _, e := range errs {
+			addFailure(e)
+		}
+	}
+
+	if configOption.Strict && len(failures) > 0 {
+		return nil, newConfigErrors(failures)
+	}
+
+	return descriptions, newConfigErrors(failures)
+}
+
+// describeLogger resolves the logger named loggerName, the top level logger is used when loggerName is empty.
+// it returns the description and the appenders failed to resolve,
+// or error if the logger is not defined or none of its appenders is resolved.
+func describeLogger(config *viper.Viper, sources []ConfigSource, loggerName string) (*LoggerDescription, []error,
+	error) {
+	appenders, errors, err := describeLoggerAppenders(config, loggerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	failures := make([]error, 0, len(errors))
+	for _, v := range errors {
+		failures = append(failures, v)
+	}
+
+	description := &LoggerDescription{
+		Name:      loggerName,
+		Appenders: make([]AppenderDescription, 0, len(appenders)),
+		Options:   describeLoggerOptions(config, loggerName),
+		Levels:    loadNamedLevels(config, loggerName),
+		Sources:   sources,
+	}
+
+	for _, appender := range appenders {
+		description.Appenders = append(description.Appenders, *appender)
+	}
+	sort.Slice(description.Appenders, func(i, j int) bool {
+		return description.Appenders[i].Name < description.Appenders[j].Name
+	})
+
+	return description, failures, nil
+}
+
+// loggerNames returns the names of the loggers defined in config, the top level logger is named by empty string
+// and comes first if the top level 'appenders' exists. the loggers in 'loggers' section are sorted by name.
+func loggerNames(config *viper.Viper) []string {
+	var names []string
+	if config.IsSet("appenders") {
+		names = append(names, "")
+	}
+
+	return append(names, definedLoggerNames(config)...)
+}
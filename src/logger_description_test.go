@@ -0,0 +1,209 @@
+package cfzap
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDescribeLogger(t *testing.T) {
+	// the directory is not created yet, it should not be created by description.
+	dir := filepath.Join(t.TempDir(), "cfzap-describe-test")
+	filename := filepath.ToSlash(filepath.Join(dir, "logs", "test.log"))
+
+	data := []byte(`
+appenders: [appender-file, appender-stdout]
+options:
+  caller: true
+  fields:
+    app: demo
+levels:
+  db.pool: warn
+appender-stdout:
+  target: Stdout
+  encoderType: console
+  threshold: Warn
+  encoderConfig: encoderConfig
+appender-file:
+  target: lumberjack
+  logLevel: Debug
+  encoderConfig: encoderConfig
+lumberjack:
+  filename: ` + filename + `
+  maxSize: 1
+  compress: true
+encoderConfig:
+  messageKey: MSG
+  encodeLevel: Capital
+`)
+	description, err := DescribeLogger(NewConfigOption(WithData(data, "yaml")), "")
+	assert.Nil(t, err, "the config should be described.")
+
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err), "no directory should be created.")
+
+	assert.Equal(t, "", description.Name)
+	assert.Equal(t, OptionsDescription{Caller: true, Fields: map[string]string{"app": "demo"}}, description.Options)
+	assert.Equal(t, map[string]zapcore.Level{"db.pool": zapcore.WarnLevel}, description.Levels)
+	assert.Equal(t, 1, len(description.Sources))
+
+	encoder := EncoderDescription{MessageKey: "MSG", EncodeLevel: "Capital"}
+	assert.Equal(t, []AppenderDescription{
+		{
+			Name:          "appender-file",
+			Target:        "lumberjack",
+			TargetKind:    TargetFile,
+			File:          &FileDescription{Filename: filename, MaxSize: 1, Compress: true},
+			Level:         zapcore.DebugLevel,
+			Threshold:     zapcore.DebugLevel,
+			EncoderType:   "json",
+			EncoderConfig: "encoderConfig",
+			Encoder:       encoder,
+		},
+		{
+			Name:          "appender-stdout",
+			Target:        "Stdout",
+			TargetKind:    TargetStdout,
+			Level:         zapcore.InfoLevel,
+			Threshold:     zapcore.WarnLevel,
+			EncoderType:   "console",
+			EncoderConfig: "encoderConfig",
+			Encoder:       encoder,
+		},
+	}, description.Appenders)
+}
+
+func TestDescribeNamedLogger(t *testing.T) {
+	option := NewConfigOption(WithFileName("logger_config"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+
+	description, err := DescribeLogger(option, "app")
+	assert.Nil(t, err, "logger [app] should be described.")
+	assert.Equal(t, "app", description.Name)
+	assert.True(t, description.Options.Caller)
+	assert.Equal(t, 2, len(description.Appenders))
+	assert.Equal(t, TargetStderr, description.Appenders[0].TargetKind)
+	assert.Equal(t, zapcore.WarnLevel, description.Appenders[0].Level)
+	assert.Equal(t, TargetStdout, description.Appenders[1].TargetKind)
+
+	_, err = DescribeLogger(option, "missing")
+	assert.Equal(t, "logger [missing] is not defined in section [loggers]", err.Error())
+
+	_, err = DescribeLogger(option, "broken")
+	var missing *MissingSectionError
+	assert.True(t, errors.As(err, &missing), "the missing appender should be reported.")
+	assert.Equal(t, "appender-missing", missing.Key)
+}
+
+func TestDescribeLoggerWithFailedAppender(t *testing.T) {
+	data := []byte(`
+appenders: [appender-stdout, appender-file]
+appender-stdout:
+  target: stdout
+  encoderConfig: encoderConfig
+appender-file:
+  target: lumberjack
+  encoderConfig: encoderConfig
+encoderConfig:
+  messageKey: MSG
+`)
+	description, err := DescribeLogger(NewConfigOption(WithData(data, "yaml")), "")
+	assert.NotNil(t, description, "the appenders resolved should be described.")
+	assert.Equal(t, 1, len(description.Appenders))
+	assert.Equal(t, "appender-stdout", description.Appenders[0].Name)
+
+	var target *UnknownTargetError
+	assert.True(t, errors.As(err, &target), "the failed appender should be reported.")
+	assert.Equal(t, "appender-file", target.Appender)
+
+	description, err = DescribeLogger(NewConfigOption(WithData(data, "yaml"), WithStrict(true)), "")
+	assert.Nil(t, description, "nothing should be described in strict mode.")
+	assert.True(t, errors.As(err, &target), "the failed appender should be reported in strict mode.")
+}
+
+func TestDescribeLoggers(t *testing.T) {
+	option := NewConfigOption(WithFileName("logger_config"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+
+	descriptions, err := DescribeLoggers(option)
+	assert.Equal(t, 3, len(descriptions), "the loggers except [broken] should be described.")
+	assert.Equal(t, "", descriptions[0].Name)
+	assert.Equal(t, "app", descriptions[1].Name)
+	assert.Equal(t, "audit", descriptions[2].Name)
+	assert.Equal(t, "appender-stderr", descriptions[2].Appenders[0].Name)
+
+	var missing *MissingSectionError
+	assert.True(t, errors.As(err, &missing), "the missing appender of [broken] should be reported.")
+	assert.Equal(t, "appender-missing", missing.Key)
+
+	descriptions, err = DescribeLoggers(cloneConfigOption(option, WithStrict(true)))
+	assert.Nil(t, descriptions, "nothing should be described in strict mode.")
+	assert.NotNil(t, err, "the missing appender should be reported in strict mode.")
+
+	_, err = DescribeLoggers(NewConfigOption(WithData([]byte("options:\n  caller: true\n"), "yaml")))
+	assert.Equal(t, "missing section [appenders]", err.Error())
+}
+
+func TestDescribeDottedLoggerName(t *testing.T) {
+	data := []byte(`
+appenders: [appender-stdout]
+loggers:
+  audit.sql:
+    appenders: [appender-stdout]
+  db:
+    pool:
+      appenders: [appender-stdout]
+appender-stdout:
+  target: stdout
+  encoderConfig: encoderConfig
+encoderConfig:
+  messageKey: MSG
+`)
+	option := NewConfigOption(WithData(data, "yaml"), WithStrict(true))
+	descriptions, err := DescribeLoggers(option)
+	assert.Nil(t, err, "the dotted logger name should not yield a phantom logger.")
+	names := make([]string, len(descriptions))
+	for i, description := range descriptions {
+		names[i] = description.Name
+	}
+	assert.Equal(t, []string{"", "audit.sql", "db.pool"}, names)
+
+	// the converted config keeps the logger names.
+	converted, err := ConvertConfig(NewConfigOption(WithData(data, "yaml")), "yaml")
+	assert.Nil(t, err, "fail to convert config.")
+	_, err = DescribeLogger(NewConfigOption(WithData(converted, "yaml")), "audit.sql")
+	assert.Nil(t, err, "the logger with dotted name should be in the converted config.")
+
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+	_, err = r.GetNamedLogger(NewConfigOption(WithData(data, "yaml")), "audit.sql")
+	assert.Nil(t, err, "the logger with dotted name should be created.")
+}
+
+func TestDescribeLoggerWithNilOption(t *testing.T) {
+	// the default config file cfzap.yaml is used.
+	description, err := DescribeLogger(nil, "")
+	assert.Nil(t, err, "the default ConfigOption should be used.")
+	assert.NotEmpty(t, description.Appenders)
+
+	descriptions, err := DescribeLoggers(nil)
+	assert.Nil(t, err, "the default ConfigOption should be used.")
+	assert.NotEmpty(t, descriptions)
+}
+
+func TestDescribeLoggerWithInvalidAppenders(t *testing.T) {
+	for _, data := range []string{"appenders: [1]", "appenders: [{name: appender-stdout}]", "appenders:\n- \n"} {
+		option := NewConfigOption(WithData([]byte(data), "yaml"))
+
+		description, err := DescribeLogger(option, "")
+		assert.Nil(t, description, "nothing should be described: %s", data)
+		assert.NotNil(t, err, "the invalid appender list should be reported: %s", data)
+
+		descriptions, err := DescribeLoggers(option)
+		assert.Nil(t, descriptions, "nothing should be described: %s", data)
+		assert.NotNil(t, err, "the invalid appender list should be reported: %s", data)
+	}
+}
@@ -1,10 +1,18 @@
 package cfzap
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 const testFilePath = "test_config_file"
@@ -25,15 +33,38 @@ func TestGetLoggerFromDefaultConfig(t *testing.T) {
 	// write to log file.
 	logger.Debug("return exist logger because no config is specified from the second time")
 
-	logger2, err = GetLogger(NewConfigOption(WithCreateNew(true)))
+	holder := unnamedEntry(defaultRegistry, NewConfigOption()).holder
+	generation := holder.load().generation
+	_, err = GetLogger(NewConfigOption(WithCreateNew(true)))
 	assert.Nil(t, err, "fail to get create now logger according to ConfigOption.CreateNew.")
-	assert.NotEqual(t, logger, logger2, "the new logger should not be same as exist one.")
+	assert.Equal(t, generation+1, holder.load().generation, "the exist logger should use the new core.")
+}
+
+func TestGetLoggerKeepsHandle(t *testing.T) {
+	option := NewConfigOption(WithFileName("appender_config_ok"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+	logger, err := GetLogger(option)
+	assert.Nil(t, err, "fail to create a new logger from appender_config_ok.yaml.")
+	child := logger.Named("child").With(zap.String("a", "b"))
+
+	core, logs := observer.New(zap.DebugLevel)
+	_ = unnamedEntry(defaultRegistry, option).holder.swap(core, nil)
+
+	logger.Debug("write to the new core")
+	child.Info("write to the new core from child")
+	assert.Equal(t, 2, logs.Len(), "the exist logger and its child should write to the new core.")
+	assert.Equal(t, "child", logs.All()[1].LoggerName, "logger name should be kept.")
+
+	// rebuild the logger, the exist logger should not write to the swapped core any more.
+	_, err = GetLogger(cloneConfigOption(option, WithCreateNew(true)))
+	assert.Nil(t, err, "fail to create the logger again.")
+	child.Info("write to the rebuilt core")
+	assert.Equal(t, 2, logs.Len(), "the exist logger should write to the rebuilt core.")
 }
 
 func TestGetLoggerFromDefaultYaml(t *testing.T) {
-	// this commented code below reads cfzap.json but nto cfzap.yaml. I think this is a bug in Viper.
-	// configOption := NewConfigOption(WithFileName("cfzap"), WithFileExt("yaml"))
-	configOption := NewConfigOption(WithFileName("cfzap.yaml"), WithFileExt("yaml"))
+	// NewConfigOption(WithFileName("cfzap"), WithFileExt("yaml")) reads cfzap.json but not cfzap.yaml,
+	// because Viper searches all extensions. use the full path to load exactly cfzap.yaml.
+	configOption := NewConfigOption(WithFilePath("cfzap.yaml"))
 	logger, err := GetLogger(configOption)
 	assert.Nil(t, err, "fail to create a new logger from cfzap.yaml.")
 
@@ -56,8 +87,14 @@ func TestGetLoggerForMissingAppender(t *testing.T) {
 	configOption.FilePaths = []string{testFilePath}
 
 	logger, err := GetLogger(configOption)
-	assert.Nil(t, err, "should be failed because there's a missing appender.")
+	var configErrors *ConfigErrors
+	assert.True(t, errors.As(err, &configErrors), "the missing appender should be reported.")
+	assert.Equal(t, 1, len(configErrors.Errors), "there's only one missing appender.")
+	var appenderErr *AppenderError
+	assert.True(t, errors.As(err, &appenderErr), "the error of appender should be listed.")
+	assert.Equal(t, "appender-file-missing", appenderErr.Appender, "wrong appender reported.")
 	assert.NotNil(t, logger, "there is still a valid appender.")
+	assert.NotSame(t, defaultLogger, logger, "the logger should be created with the valid appender.")
 }
 
 func TestGetLoggerWithoutAppenders(t *testing.T) {
@@ -69,3 +106,160 @@ func TestGetLoggerWithoutAppenders(t *testing.T) {
 	assert.NotNil(t, err, "there's no appenders section defined.")
 	assert.Equal(t, "missing section [appenders]", err.Error(), "wrong error message")
 }
+
+func TestGetLoggerWithWatch(t *testing.T) {
+	const content = `
+appenders:
+- appender-stdout
+appender-stdout:
+  logLevel: %s
+  encoderConfig: encoderConfig
+  target: stdout
+encoderConfig:
+  messageKey: MSG
+`
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "watch.yaml")
+	assert.Nil(t, os.WriteFile(filename, []byte(fmt.Sprintf(content, "Info")), 0644))
+
+	// the handlers are different closures of the same function.
+	newHandler := func(reloadErrors chan error) func(error) {
+		return func(err error) { reloadErrors <- err }
+	}
+	oldErrors, reloadErrors := make(chan error, 10), make(chan error, 10)
+	handler := newHandler(reloadErrors)
+
+	r := NewRegistry()
+	logger, err := r.GetLogger(NewConfigOption(
+		WithFileName("watch"),
+		WithFileExt("yaml"),
+		WithFilePaths(dir),
+		WithWatch(true),
+		WithReloadErrorHandler(newHandler(oldErrors))))
+	assert.Nil(t, err, "fail to create a new logger with watch.")
+	assert.False(t, logger.Core().Enabled(zap.DebugLevel), "debug level should not be enabled.")
+
+	// the cached logger is returned, and it uses the new handler.
+	cached, err := r.GetLogger(NewConfigOption(
+		WithFileName("watch"),
+		WithFileExt("yaml"),
+		WithFilePaths(dir),
+		WithWatch(true),
+		WithReloadErrorHandler(handler)))
+	assert.Nil(t, err, "fail to get the cached logger.")
+	assert.Same(t, logger, cached, "the handler should not identify the cached logger.")
+
+	// the logger returned before should use the new config.
+	assert.Nil(t, os.WriteFile(filename, []byte(fmt.Sprintf(content, "Debug")), 0644))
+	assert.Eventually(t, func() bool { return logger.Core().Enabled(zap.DebugLevel) },
+		5*time.Second, 50*time.Millisecond, "debug level should be enabled after reloading.")
+
+	// the previous config should be kept when the new one is invalid.
+	assert.Nil(t, os.WriteFile(filename, []byte("appenders: []"), 0644))
+	select {
+	case err := <-reloadErrors:
+		assert.NotNil(t, err, "the reload error should be reported.")
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the reload error should be reported to the handler.")
+	}
+	assert.True(t, logger.Core().Enabled(zap.DebugLevel), "the previous config should be kept.")
+	assert.Equal(t, 0, len(oldErrors), "the handler replaced should not be called.")
+
+	entry := unnamedEntry(r, NewConfigOption(WithFileName("watch"), WithFileExt("yaml"), WithFilePaths(dir), WithWatch(true)))
+	w, generation := entry.watcher, entry.holder.load().generation
+	assert.Nil(t, r.Shutdown(context.Background()), "fail to shutdown.")
+	assert.Nil(t, entry.watcher, "the config file should not be watched after shutdown.")
+
+	// the error of reloading before shutdown is not reported after that.
+	r.reportReloadError(entry, w, generation, errors.New("reloaded before shutdown"))
+	for len(reloadErrors) > 0 {
+		assert.NotEqual(t, "reloaded before shutdown", (<-reloadErrors).Error(), "the closed logger should not report errors.")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	const content = `
+appenders:
+- appender-file
+appender-file:
+  logLevel: Debug
+  encoderConfig: encoderConfig
+  target: lumberjack2
+lumberjack2:
+  filename: %s
+encoderConfig:
+  messageKey: MSG
+`
+	dir := t.TempDir()
+	logFile := filepath.ToSlash(filepath.Join(dir, "shutdown.log"))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "shutdown.yaml"), []byte(fmt.Sprintf(content, logFile)), 0644))
+
+	logger, err := GetLogger(NewConfigOption(WithFileName("shutdown"), WithFileExt("yaml"), WithFilePaths(dir)))
+	assert.Nil(t, err, "fail to create a new logger from shutdown.yaml.")
+	logger.Info("before shutdown")
+
+	assert.Nil(t, Shutdown(context.Background()), "fail to shutdown.")
+	info, err := os.Stat(logFile)
+	assert.Nil(t, err, "log file should be created.")
+	size := info.Size()
+	assert.True(t, size > 0, "log file should not be empty.")
+
+	// the logger should discard entries after shutdown.
+	logger.Info("after shutdown")
+	info, _ = os.Stat(logFile)
+	assert.Equal(t, size, info.Size(), "nothing should be written after shutdown.")
+
+	// a new logger should be created after shutdown.
+	logger2, err := GetLogger(nil)
+	assert.Nil(t, err, "fail to create a new logger after shutdown.")
+	assert.NotNil(t, logger2, "the new logger should not be nil.")
+}
+
+func TestGetNamedLogger(t *testing.T) {
+	option := NewConfigOption(WithFileName("logger_config"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+
+	app, err := GetNamedLogger(option, "app")
+	assert.Nil(t, err, "fail to get logger app.")
+	assert.True(t, app.Core().Enabled(zap.DebugLevel), "logger app should enable debug level.")
+
+	audit, err := GetNamedLogger(option, "audit")
+	assert.Nil(t, err, "fail to get logger audit.")
+	assert.False(t, audit.Core().Enabled(zap.InfoLevel), "logger audit should not enable info level.")
+
+	logger, err := GetNamedLogger(option, "app")
+	assert.Nil(t, err, "fail to get logger app again.")
+	assert.Same(t, app, logger, "the cached logger app should be returned.")
+
+	logger, err = GetLogger(option)
+	assert.Nil(t, err, "fail to get top level logger.")
+	assert.NotSame(t, app, logger, "the top level logger should be different from logger app.")
+
+	_, err = GetNamedLogger(option, "unknown")
+	assert.NotNil(t, err, "logger unknown is not defined.")
+}
+
+func TestNamedLevelsOf(t *testing.T) {
+	logger, err := GetLogger(NewConfigOption(WithFileName("level_config"), WithFileExt("yaml"), WithFilePaths(testFilePath)))
+	assert.Nil(t, err, "fail to create a new logger from level_config.yaml.")
+
+	levels, ok := NamedLevelsOf(logger.With(zap.String("a", "b")))
+	assert.True(t, ok, "the logger should have named levels.")
+	level, _ := levels.Level("db.pool")
+	assert.Equal(t, zap.DebugLevel, level, "the level of db.pool should be loaded from config file.")
+
+	_, ok = NamedLevelsOf(zap.NewNop())
+	assert.False(t, ok, "the logger not created by cfzap should not have named levels.")
+}
+
+func TestGetLoggerFromData(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testFilePath, "logger_config.yaml"))
+	assert.Nil(t, err, "fail to read test file.")
+
+	option := NewConfigOption(WithData(data, "yaml"))
+	logger, err := GetLogger(option)
+	assert.Nil(t, err, "fail to create a new logger from byte slice.")
+
+	logger2, err := GetLogger(NewConfigOption(WithData(data, "yaml")))
+	assert.Nil(t, err, "fail to get logger from byte slice.")
+	assert.Same(t, logger, logger2, "the cached logger should be returned for the same data.")
+}
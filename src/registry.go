@@ -0,0 +1,623 @@
+package cfzap
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Registry caches loggers created from config files, so loggers from different config files can be used side by side.
+// A logger is cached either by the name given to Register(), or by its ConfigOption when it is created by GetLogger().
+type Registry struct {
+	// the loggers registered by name.
+	named map[string]*registryEntry
+	// the loggers created by GetLogger(), each of them has a different ConfigOption.
+	unnamed []*registryEntry
+	// the loggers created by NewLoggerFromViper(), each of them has a different viper and key.
+	external []*registryEntry
+
+	lock sync.Mutex
+}
+
+// registryEntry is a cached logger and the resources used by it.
+type registryEntry struct {
+	// the name to identify the entry in error messages.
+	name string
+	// the logger name in 'loggers' section of the config file, empty for the top level logger.
+	loggerName string
+	// the config given to NewLoggerFromViper(), nil for the other loggers.
+	viperConfig *viper.Viper
+	// the ConfigOption used to create the logger, CreateNew is always false. Note, it is not a pointer.
+	option ConfigOption
+	// the logger returned last time, nil if the entry has been closed.
+	logger *zap.Logger
+	// the holder of the core used by all loggers returned for the entry.
+	// the core in it is replaced every time the logger is rebuilt.
+	holder *coreHolder
+	// the appenders used by the core in holder, they are closed after the core is replaced.
+	appenders map[string]*appenderConfig
+	// the entries being written by the core in holder.
+	usage *coreUsage
+	// the appenders used by the cores replaced, they are closed when the entries in progress are written, or by close().
+	retired []*retiredAppenders
+	// the levels by logger names used by the core in holder, it's reset when the core is replaced.
+	levels *NamedLevels
+	// the options of the logger returned last time.
+	options OptionsDescription
+	// the errors of the appenders failed to load when the logger was built last time.
+	failures []error
+	// the watcher of the config file, nil if the config file is not watched.
+	watcher *configWatcher
+}
+
+// NewRegistry creates and returns an empty Registry object.
+func NewRegistry() *Registry {
+	return &Registry{named: make(map[string]*registryEntry)}
+}
+
+// GetLogger returns a logger according to the config file, the logger is cached by configOption.
+// If configOption is nil, the default ConfigOption is used.
+// If 'createNew' is false, then trying to return the exist logger created from the same ConfigOption before.
+// When the logger is created again, the loggers returned before, including their children created by With() or Named(),
+// write to the new appenders too. Only their options, such as 'caller', are not changed.
+// The logger is kept in the registry until RemoveLogger() or Shutdown() is called, see GetNamedLogger().
+// In theory, even with an error, the returned logger will not be nil.
+// If only some appenders failed to load, the logger using the others is returned with *ConfigErrors listing the failures,
+// and the same failures are returned whenever the cached logger is returned.
+func (r *Registry) GetLogger(configOption *ConfigOption) (*zap.Logger, error) {
+	return r.GetNamedLogger(configOption, "")
+}
+
+// GetNamedLogger returns the logger named loggerName in the 'loggers' section of the config file,
+// the logger is cached by configOption and loggerName.
+// The logger defined by the top level 'appenders' and 'options' is returned when loggerName is empty.
+// Note, the ConfigOption with Reader never equals another one, so every call with it caches a new logger,
+// call RemoveLogger() when it's no longer used, or use Register() instead.
+// It works as GetLogger() in other aspects.
+func (r *Registry) GetNamedLogger(configOption *ConfigOption, loggerName string) (*zap.Logger, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if configOption == nil { // using default value if it is not provided.
+		configOption = NewConfigOption()
+	}
+
+	var entry *registryEntry
+	for _, e := range r.unnamed {
+		if e.loggerName == loggerName && sameConfigOption(&e.option, configOption) {
+			entry = e
+			break
+		}
+	}
+
+	if entry == nil {
+		entry = &registryEntry{name: configOption.FileName, loggerName: loggerName, levels: NewNamedLevels()}
+		if loggerName != "" {
+			entry.name += "." + loggerName
+		}
+
+		failures, err := r.load(entry, configOption)
+		if err != nil {
+			return defaultLogger, err
+		}
+
+		r.unnamed = append(r.unnamed, entry)
+		return entry.logger, newConfigErrors(failures)
+	}
+
+	return r.get(entry, configOption)
+}
+
+// NewLoggerFromViper creates a logger from the sub-tree of config under key, such as 'logging'.
+// The whole config is used when key is empty. The sub-tree has the same format as the config file,
+// and the variables in it are expanded too. config is not changed.
+// The logger is cached by config and key. Calling it again with the same config and key creates the logger again,
+// and the loggers returned before write to the new appenders too, so the appenders are not leaked.
+// In theory, even with an error, the returned logger will not be nil.
+// If only some appenders failed to load, the logger using the others is returned with *ConfigErrors listing the failures.
+func (r *Registry) NewLoggerFromViper(config *viper.Viper, key string) (*zap.Logger, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	section := config
+	if key != "" {
+		if section = config.Sub(key); section == nil {
+			return defaultLogger, &MissingSectionError{Key: key}
+		}
+	}
+
+	// copy the settings, so the variables can be expanded without changing config.
+	subConfig := viper.New()
+	if err := subConfig.MergeConfigMap(section.AllSettings()); err != nil {
+		return defaultLogger, err
+	}
+	if err := expandVariables(subConfig); err != nil {
+		return defaultLogger, err
+	}
+
+	name := "viper." + key
+	var entry *registryEntry
+	for _, e := range r.external {
+		if e.viperConfig == config && e.name == name {
+			entry = e
+			break
+		}
+	}
+
+	isNew := entry == nil
+	if isNew {
+		entry = &registryEntry{name: name, viperConfig: config, levels: NewNamedLevels()}
+	}
+
+	failures, err := entry.build(subConfig, nil, false)
+	if err != nil {
+		_ = defaultLogger.Sync()
+		return defaultLogger, err
+	}
+
+	if isNew {
+		r.external = append(r.external, entry)
+	}
+
+	return entry.logger, newConfigErrors(failures)
+}
+
+// Register creates a logger according to the config file, and caches it by name.
+// If a logger with the same name and the same ConfigOption has been registered, and 'createNew' is false,
+// the registered one is returned. Otherwise, the logger is created again and the loggers returned before
+// write to the new appenders too.
+// In theory, even with an error, the returned logger will not be nil.
+// If only some appenders failed to load, the logger using the others is returned with *ConfigErrors listing the failures,
+// and the same failures are returned whenever the cached logger is returned.
+func (r *Registry) Register(name string, configOption *ConfigOption) (*zap.Logger, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if configOption == nil { // using default value if it is not provided.
+		configOption = NewConfigOption()
+	}
+
+	entry, ok := r.named[name]
+	if !ok {
+		entry = &registryEntry{name: name, levels: NewNamedLevels()}
+		failures, err := r.load(entry, configOption)
+		if err != nil {
+			return defaultLogger, err
+		}
+
+		r.named[name] = entry
+		return entry.logger, newConfigErrors(failures)
+	}
+
+	if !sameConfigOption(&entry.option, configOption) {
+		// always create it again when the ConfigOption is changed.
+		configOption = cloneConfigOption(configOption, WithCreateNew(true))
+	}
+
+	return r.get(entry, configOption)
+}
+
+// Get returns the logger registered by name.
+// It returns false if there's no such logger, or the logger has been closed by Shutdown().
+func (r *Registry) Get(name string) (*zap.Logger, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if entry, ok := r.named[name]; ok && entry.logger != nil {
+		return entry.logger, true
+	}
+
+	return nil, false
+}
+
+// Remove flushes and closes the appenders of the logger registered by name, then removes it from the registry.
+// The loggers returned before discard all entries after that.
+// It returns *ShutdownError if some of the appenders failed to be flushed or closed.
+func (r *Registry) Remove(name string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry, ok := r.named[name]
+	if !ok {
+		return nil
+	}
+
+	delete(r.named, name)
+
+	if errors := entry.close(); len(errors) > 0 {
+		return &ShutdownError{Errors: errors}
+	}
+
+	return nil
+}
+
+// RemoveLogger flushes and closes the appenders of the logger created by GetLogger(), GetNamedLogger()
+// or NewLoggerFromViper(), then removes it from the registry. The logger can be any one returned for it,
+// including its children created by With() or Named(), and they all discard the entries after that.
+// Nothing is done if the logger is not in the registry.
+// It returns *ShutdownError if some of the appenders failed to be flushed or closed.
+func (r *Registry) RemoveLogger(logger *zap.Logger) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	holder, ok := holderOf(logger)
+	if !ok {
+		return nil
+	}
+
+	var entry *registryEntry
+	r.unnamed, entry = removeEntry(r.unnamed, holder)
+	if entry == nil {
+		r.external, entry = removeEntry(r.external, holder)
+	}
+	if entry == nil {
+		return nil
+	}
+
+	if errors := entry.close(); len(errors) > 0 {
+		return &ShutdownError{Errors: errors}
+	}
+
+	return nil
+}
+
+// removeEntry removes the entry using the holder from entries.
+// it returns the entries left and the entry removed, which is nil if no entry uses the holder.
+func removeEntry(entries []*registryEntry, holder *coreHolder) ([]*registryEntry, *registryEntry) {
+	for i, entry := range entries {
+		if entry.holder == holder {
+			return append(entries[:i:i], entries[i+1:]...), entry
+		}
+	}
+
+	return entries, nil
+}
+
+// Shutdown flushes and closes all appenders used by the loggers in the registry, and stops watching the config files.
+// The loggers returned before discard all entries after that, until they are created again.
+// The entries checked but not written yet are dropped.
+// Note, lumberjack never stops the goroutine removing the old log files, even after the file is closed,
+// so each log file opened leaves one goroutine behind, including the ones opened before the logger was rebuilt.
+// It returns ctx.Err() if ctx is done before all appenders are closed,
+// or *ShutdownError if some of the appenders failed.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	go func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		entries := r.entries()
+
+		errors := make(map[string]error)
+		for _, entry := range entries {
+			// the appender names may be same in different loggers.
+			for k, v := range entry.close() {
+				errors[entry.name+"/"+k] = v
+			}
+		}
+
+		if len(errors) > 0 {
+			done <- &ShutdownError{Errors: errors}
+		} else {
+			done <- nil
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// entries returns all entries in the registry, the registered ones are sorted by name and come first.
+// the caller should hold the lock.
+func (r *Registry) entries() []*registryEntry {
+	names := make([]string, 0, len(r.named))
+	for name := range r.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]*registryEntry, 0, len(r.named)+len(r.unnamed)+len(r.external))
+	for _, name := range names {
+		entries = append(entries, r.named[name])
+	}
+	entries = append(entries, r.unnamed...)
+	entries = append(entries, r.external...)
+
+	return entries
+}
+
+// get returns the logger of the entry, it creates the logger again if required.
+// the caller should hold the lock.
+func (r *Registry) get(entry *registryEntry, configOption *ConfigOption) (*zap.Logger, error) {
+	// the entry closed by Shutdown() must be created again.
+	// the appenders failed to load are reported every time the cached logger is returned.
+	if entry.logger != nil && !configOption.CreateNew {
+		// the handler is not compared to find the entry, the last one is used.
+		entry.option.ReloadErrorHandler = configOption.ReloadErrorHandler
+		return entry.logger, newConfigErrors(entry.failures)
+	}
+
+	failures, err := r.load(entry, configOption)
+	if err != nil {
+		return defaultLogger, err
+	}
+
+	return entry.logger, newConfigErrors(failures)
+}
+
+// load creates the logger of the entry according to the config file.
+// the entry is not changed when error occurs.
+// the caller should hold the lock.
+// it returns the errors of the appenders failed to load, and error object.
+func (r *Registry) load(entry *registryEntry, configOption *ConfigOption) ([]error, error) {
+	config, sources, err := readConfigFile(configOption)
+	if err != nil {
+		defaultLogger.Warn("fail to load logger config: " + err.Error())
+		return nil, err
+	}
+
+	failures, err := entry.build(config, sources, configOption.Strict)
+	if err != nil {
+		_ = defaultLogger.Sync()
+		return nil, err
+	}
+
+	// the old config file is no longer used by the new logger.
+	entry.stopWatching()
+
+	// clone and save the new configOption.
+	entry.option = *cloneConfigOption(configOption, WithCreateNew(false))
+
+	// the config files in fs.FS and the content of io.Reader or byte slice don't change.
+	var filenames []string
+	if configOption.FS == nil {
+		for _, source := range sources {
+			if source.Path != "" {
+				filenames = append(filenames, source.Path)
+			}
+		}
+	}
+
+	if configOption.Watch && len(filenames) == 0 {
+		// the config is not read from a file.
+		defaultLogger.Warn("fail to watch logger config: only the config file in file system can be watched")
+	} else if configOption.Watch {
+		var w *configWatcher
+		w, err = watchConfigFiles(func() {
+			r.lock.Lock()
+			// ignore the change when the logger has been rebuilt from another config or closed.
+			if w != entry.watcher {
+				r.lock.Unlock()
+				return
+			}
+			reloadErr := entry.reload()
+			generation := entry.holder.load().generation
+			r.lock.Unlock()
+
+			if reloadErr != nil {
+				r.reportReloadError(entry, w, generation, reloadErr)
+			}
+		}, filenames...)
+
+		if err != nil {
+			// the logger still works, only it will not be reloaded.
+			defaultLogger.Warn("fail to watch logger config: " + err.Error())
+		}
+		entry.watcher = w
+	}
+
+	return failures, nil
+}
+
+// reportReloadError calls the ReloadErrorHandler of the entry with err, unless the entry has been removed,
+// closed or rebuilt since it was reloaded by the watcher, that is when its watcher or the generation
+// of its core is changed.
+func (r *Registry) reportReloadError(entry *registryEntry, w *configWatcher, generation uint64, err error) {
+	r.lock.Lock()
+	handler := entry.option.ReloadErrorHandler
+	if w != entry.watcher || entry.holder.load().generation != generation {
+		handler = nil
+	}
+	r.lock.Unlock()
+
+	// the handler may create loggers, so it's called without the lock.
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// reload reads the config file again and swaps the new core into the holder.
+// the previous core is kept when the config file is invalid.
+// the caller should hold the lock of the registry.
+// it returns the error of reloading, or *ConfigErrors listing the appenders failed to load.
+func (entry *registryEntry) reload() error {
+	config, sources, err := readConfigFile(&entry.option)
+	if err != nil {
+		defaultLogger.Warn("fail to reload logger config, keep using the previous one: " + err.Error())
+		return err
+	}
+
+	failures, err := entry.build(config, sources, entry.option.Strict)
+	if err != nil {
+		defaultLogger.Warn("fail to reload logger config, keep using the previous one: " + err.Error())
+		return err
+	}
+
+	return newConfigErrors(failures)
+}
+
+// build creates the logger of the entry from config, and swaps the new core into the holder.
+// the loggers returned before keep their options, only the new one uses the options in config.
+// sources describes the config files loaded, they are logged at debug level by the new logger.
+// no logger is created if any appender fails to load in strict mode.
+// the entry is not changed when error occurs.
+// the caller should hold the lock of the registry.
+// it returns the errors of the appenders failed to load, and error object.
+func (entry *registryEntry) build(config *viper.Viper, sources []ConfigSource, strict bool) ([]error, error) {
+	usage := new(coreUsage)
+	core, appenders, failures, err := loadCore(config, entry.loggerName, entry.levels, usage, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	// the levels changed at runtime are discarded.
+	entry.levels.reset(loadNamedLevels(config, entry.loggerName))
+
+	// all loggers returned before write to the new core from now on.
+	entry.replaceCore(core, usage, appenders)
+
+	// create a new logger.
+	entry.holder.sources.Store(sources)
+	entry.options = describeLoggerOptions(config, entry.loggerName)
+	entry.failures = failures
+	entry.logger = zap.New(newSwapCore(entry.holder), newOptions(entry.options)...)
+
+	for _, source := range sources {
+		entry.logger.Debug("logger config loaded", source.fields()...)
+	}
+
+	return failures, nil
+}
+
+// replaceCore replaces the core in holder, then retires the appenders used by the old core.
+// they are closed as soon as the entries checked by the old core have been written.
+// usage counts the entries being written by the new core.
+// the caller should hold the lock of the registry.
+func (entry *registryEntry) replaceCore(core zapcore.Core, usage *coreUsage, appenders map[string]*appenderConfig) {
+	if entry.holder == nil {
+		entry.holder = newCoreHolder(core, usage, entry.levels)
+	} else {
+		_ = entry.holder.swap(core, usage).Sync()
+	}
+
+	// forget the appenders closed already.
+	retired := entry.retired[:0]
+	for _, r := range entry.retired {
+		if !r.isClosed() {
+			retired = append(retired, r)
+		}
+	}
+	entry.retired = retired
+
+	if len(entry.appenders) > 0 {
+		entry.retired = append(entry.retired, retireAppenders(entry.appenders, entry.usage))
+	}
+
+	entry.appenders = appenders
+	entry.usage = usage
+	entry.holder.appenders.Store(appenders)
+}
+
+// stopWatching stops watching the config file.
+func (entry *registryEntry) stopWatching() {
+	if entry.watcher != nil {
+		entry.watcher.close()
+		entry.watcher = nil
+	}
+}
+
+// description describes the logger of the entry as it's used now, the levels changed at runtime included.
+// the caller should hold the lock of the registry.
+func (entry *registryEntry) description() *LoggerDescription {
+	description := &LoggerDescription{
+		Name:      entry.loggerName,
+		Appenders: make([]AppenderDescription, 0, len(entry.appenders)),
+		Options:   entry.options,
+		Levels:    entry.levels.Levels(),
+	}
+	if entry.holder != nil {
+		description.Sources = entry.holder.loadSources()
+	}
+
+	for _, appender := range entry.appenders {
+		appenderDescription := *appender.description
+		appenderDescription.Level = appender.logLevel.Level()
+		description.Appenders = append(description.Appenders, appenderDescription)
+	}
+	sort.Slice(description.Appenders, func(i, j int) bool {
+		return description.Appenders[i].Name < description.Appenders[j].Name
+	})
+
+	return description
+}
+
+// close stops watching the config file, then flushes and closes all appenders.
+// the loggers returned before discard all entries after that.
+// it returns the errors of the failed appenders.
+// the caller should hold the lock of the registry.
+func (entry *registryEntry) close() map[string]error {
+	entry.stopWatching()
+
+	var errors map[string]error
+	if entry.holder != nil {
+		_ = entry.holder.swap(zapcore.NewNopCore(), nil)
+		entry.holder.appenders.Store(map[string]*appenderConfig(nil))
+		errors = closeAppenders(entry.appenders)
+	}
+
+	// don't wait for the retired appenders.
+	for _, r := range entry.retired {
+		r.close()
+	}
+
+	// the logger must be created again before it can be used.
+	entry.logger = nil
+	entry.appenders = nil
+	entry.usage = nil
+	entry.retired = nil
+
+	return errors
+}
+
+// retiredAppenders are the appenders used by a replaced core,
+// they are closed when the entries checked by the core have been written.
+type retiredAppenders struct {
+	appenders map[string]*appenderConfig
+	once      sync.Once
+	// closed is 1 after the appenders are closed.
+	closed int32
+}
+
+// retireAppenders closes the appenders when no entry counted by usage is in progress, it may be at once.
+// the appenders are closed at once if usage is nil.
+func retireAppenders(appenders map[string]*appenderConfig, usage *coreUsage) *retiredAppenders {
+	r := &retiredAppenders{appenders: appenders}
+	if usage == nil {
+		r.close()
+	} else {
+		usage.retire(r.close)
+	}
+
+	return r
+}
+
+// close closes the appenders now if they are not closed yet, the failures are reported by defaultLogger.
+func (r *retiredAppenders) close() {
+	r.once.Do(func() {
+		for k, v := range closeAppenders(r.appenders) {
+			defaultLogger.Warn("fail to close appender [" + k + "]: " + v.Error())
+		}
+		atomic.StoreInt32(&r.closed, 1)
+	})
+}
+
+// isClosed checks to see if the appenders have been closed.
+func (r *retiredAppenders) isClosed() bool {
+	return atomic.LoadInt32(&r.closed) == 1
+}
+
+// sameConfigOption checks to see if two ConfigOption objects create the same logger.
+// the property CreateNew is ignored.
+func sameConfigOption(option *ConfigOption, other *ConfigOption) bool {
+	return cloneConfigOption(option, WithCreateNew(false)).equal(cloneConfigOption(other, WithCreateNew(false)))
+}
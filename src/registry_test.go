@@ -0,0 +1,270 @@
+package cfzap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// unnamedEntry returns the entry created by GetLogger() from the ConfigOption.
+func unnamedEntry(r *Registry, option *ConfigOption) *registryEntry {
+	for _, entry := range r.unnamed {
+		if sameConfigOption(&entry.option, option) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func TestRegistryGetLogger(t *testing.T) {
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	option1 := NewConfigOption(WithFileName("appender_config_ok"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+	option2 := NewConfigOption(WithFileName("appender_config_missing"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+
+	logger1, err := r.GetLogger(option1)
+	assert.Nil(t, err, "fail to create logger from option1.")
+	// the missing appender of option2 is reported, but the logger is still created.
+	logger2, err := r.GetLogger(option2)
+	assert.IsType(t, &ConfigErrors{}, err, "fail to create logger from option2.")
+	assert.Equal(t, 2, len(r.unnamed), "there should be 2 loggers in the registry.")
+
+	// the failures are returned for the cached logger too.
+	logger, err := r.GetLogger(option2)
+	assert.IsType(t, &ConfigErrors{}, err, "the failures should be returned for the cached logger.")
+	assert.Same(t, logger2, logger, "the cached logger should be returned for option2.")
+
+	// the first logger should not be evicted by the second one.
+	logger, err = r.GetLogger(option1)
+	assert.Nil(t, err, "fail to get logger from option1.")
+	assert.Same(t, logger1, logger, "the cached logger should be returned for option1.")
+
+	logger, err = r.GetLogger(cloneConfigOption(option2, WithCreateNew(true)))
+	assert.IsType(t, &ConfigErrors{}, err, "fail to create logger from option2 again.")
+	assert.NotSame(t, logger2, logger, "a new logger should be created for option2.")
+	assert.Equal(t, 2, len(r.unnamed), "there should still be 2 loggers in the registry.")
+}
+
+func TestRegistryRemoveLogger(t *testing.T) {
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	data, err := os.ReadFile(filepath.Join(testFilePath, "appender_config_ok.yaml"))
+	assert.Nil(t, err, "fail to read test file.")
+
+	// every ConfigOption with Reader caches a new logger, and none of them is closed.
+	loggers := make([]*zap.Logger, 3)
+	for i := range loggers {
+		loggers[i], err = r.GetLogger(NewConfigOption(WithReader(strings.NewReader(string(data)), "yaml")))
+		assert.Nil(t, err, "fail to create logger from reader.")
+	}
+	assert.Equal(t, 3, len(r.unnamed), "all loggers should be cached.")
+	for _, logger := range loggers {
+		assert.True(t, logger.Core().Enabled(zap.ErrorLevel), "the logger handed out should never be closed.")
+	}
+
+	// the child of the logger can be used to remove it.
+	assert.Nil(t, r.RemoveLogger(loggers[0].Named("child")), "fail to remove logger.")
+	assert.Equal(t, 2, len(r.unnamed), "the removed logger should not be cached.")
+	assert.False(t, loggers[0].Core().Enabled(zap.ErrorLevel), "the removed logger should be closed.")
+	assert.True(t, loggers[1].Core().Enabled(zap.ErrorLevel), "the other loggers should be kept.")
+
+	// nothing is done for the logger removed already, or not created by the registry.
+	assert.Nil(t, r.RemoveLogger(loggers[0]), "the removed logger should be ignored.")
+	assert.Nil(t, r.RemoveLogger(zap.NewNop()), "the logger not in the registry should be ignored.")
+	assert.Equal(t, 2, len(r.unnamed), "the other loggers should be kept.")
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+
+	_, ok := r.Get("audit")
+	assert.False(t, ok, "there should be no logger before registering.")
+
+	option := NewConfigOption(WithFileName("appender_config_ok"), WithFileExt("yaml"), WithFilePaths(testFilePath))
+	audit, err := r.Register("audit", option)
+	assert.Nil(t, err, "fail to register audit logger.")
+	app, err := r.Register("app", nil)
+	assert.Nil(t, err, "fail to register app logger.")
+	assert.NotSame(t, audit, app, "the loggers should be different.")
+
+	logger, ok := r.Get("audit")
+	assert.True(t, ok, "audit logger should be registered.")
+	assert.Same(t, audit, logger, "the registered logger should be returned.")
+
+	logger, err = r.Register("audit", option)
+	assert.Nil(t, err, "fail to register audit logger again.")
+	assert.Same(t, audit, logger, "the registered logger should be returned for the same option.")
+
+	_, err = r.Register("broken", NewConfigOption(WithFileName("no_file")))
+	assert.NotNil(t, err, "should be failed because there's no config file.")
+	_, ok = r.Get("broken")
+	assert.False(t, ok, "the failed logger should not be registered.")
+
+	assert.Nil(t, r.Remove("audit"), "fail to remove audit logger.")
+	_, ok = r.Get("audit")
+	assert.False(t, ok, "audit logger should be removed.")
+	_, ok = r.Get("app")
+	assert.True(t, ok, "app logger should not be removed.")
+
+	assert.Nil(t, r.Shutdown(context.Background()), "fail to shutdown.")
+	_, ok = r.Get("app")
+	assert.False(t, ok, "app logger should be closed.")
+}
+
+func TestRegistryNewLoggerFromViper(t *testing.T) {
+	const content = `
+server:
+  port: 8080
+logging:
+  appenders:
+  - appender-stdout
+  appender-stdout:
+    logLevel: ${TEST_CFZAP_UNDEFINED:-Warn}
+    encoderConfig: encoderConfig
+    target: stdout
+  encoderConfig:
+    messageKey: MSG
+`
+	config := viper.New()
+	config.SetConfigType("yaml")
+	assert.Nil(t, config.ReadConfig(strings.NewReader(content)), "fail to read application config.")
+
+	r := NewRegistry()
+	logger, err := r.NewLoggerFromViper(config, "logging")
+	assert.Nil(t, err, "fail to create logger from viper.")
+	assert.True(t, logger.Core().Enabled(zap.WarnLevel), "warn level should be enabled.")
+	assert.False(t, logger.Core().Enabled(zap.InfoLevel), "info level should not be enabled.")
+
+	// the application config should not be changed.
+	assert.Equal(t, "${TEST_CFZAP_UNDEFINED:-Warn}", config.GetString("logging.appender-stdout.logLevel"))
+
+	// the logger is created again for the same config and key, the one returned before writes to the new appenders.
+	logger2, err := r.NewLoggerFromViper(config, "logging")
+	assert.Nil(t, err, "fail to create logger from viper again.")
+	assert.Equal(t, 1, len(r.external), "the logger should be cached by config and key.")
+	assert.Same(t, mustHolderOf(t, logger), mustHolderOf(t, logger2), "the loggers should share the same core holder.")
+
+	_, err = r.NewLoggerFromViper(config, "server")
+	assert.NotNil(t, err, "there's no appenders in section server.")
+
+	_, err = r.NewLoggerFromViper(config, "unknown")
+	assert.NotNil(t, err, "there's no section unknown.")
+	assert.Equal(t, "missing section [unknown]", err.Error())
+
+	assert.Nil(t, r.Shutdown(context.Background()), "fail to shutdown.")
+	assert.False(t, logger.Core().Enabled(zap.ErrorLevel), "the logger should be closed by shutdown.")
+}
+
+func TestRegistryRetiresAppenders(t *testing.T) {
+	filename := filepath.ToSlash(filepath.Join(t.TempDir(), "retired.log"))
+	data := []byte(`
+appenders:
+- appender-file
+appender-file:
+  logLevel: Info
+  encoderConfig: encoderConfig
+  target: lumberjack
+lumberjack:
+  filename: ` + filename + `
+encoderConfig:
+  messageKey: MSG
+`)
+
+	r := NewRegistry()
+	option := NewConfigOption(WithData(data, "yaml"))
+	logger, err := r.GetLogger(option)
+	assert.Nil(t, err, "fail to create logger.")
+
+	// the entry is checked by the old core before the logger is rebuilt.
+	checked := logger.Check(zapcore.InfoLevel, "checked before rebuilding")
+	assert.NotNil(t, checked, "info level should be enabled.")
+
+	_, err = r.GetLogger(cloneConfigOption(option, WithCreateNew(true)))
+	assert.Nil(t, err, "fail to create logger again.")
+	entry := unnamedEntry(r, option)
+	assert.Equal(t, 1, len(entry.retired), "the old appenders should be retired.")
+	assert.False(t, entry.retired[0].isClosed(), "the old appenders should wait for the entry checked.")
+
+	checked.Write()
+	content, err := os.ReadFile(filename)
+	assert.Nil(t, err, "fail to read log file.")
+	assert.Contains(t, string(content), "checked before rebuilding", "the old appender should still write.")
+
+	// the old appenders are closed as soon as the entry checked is written.
+	old := entry.retired[0]
+	assert.True(t, old.isClosed(), "the retired appenders should be closed after the entry is written.")
+
+	// the old file is not opened again after it's closed.
+	_, err = (*old.appenders["appender-file"].writeSyncer).Write([]byte("after closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed, "the closed file should not be written.")
+
+	// without any entry in progress, the old appenders are closed at once.
+	_, err = r.GetLogger(cloneConfigOption(option, WithCreateNew(true)))
+	assert.Nil(t, err, "fail to create logger again.")
+	assert.True(t, entry.retired[len(entry.retired)-1].isClosed(), "the idle appenders should be closed at once.")
+
+	// the entry checked but never written is dropped by shutdown.
+	checked = logger.Check(zapcore.InfoLevel, "checked before shutdown")
+	_, err = r.GetLogger(cloneConfigOption(option, WithCreateNew(true)))
+	assert.Nil(t, err, "fail to create logger again.")
+	old = entry.retired[len(entry.retired)-1]
+	assert.False(t, old.isClosed(), "the old appenders should wait for the entry checked.")
+	assert.Nil(t, r.Shutdown(context.Background()), "fail to shutdown.")
+	assert.True(t, old.isClosed(), "the retired appenders should be closed by shutdown.")
+	assert.NotNil(t, checked, "info level should be enabled.")
+}
+
+func TestRegistryRetiresAppendersConcurrently(t *testing.T) {
+	filename := filepath.ToSlash(filepath.Join(t.TempDir(), "concurrent.log"))
+	data := []byte("appenders: [appender-file]\nappender-file:\n  encoderConfig: encoderConfig\n" +
+		"  target: lumberjack\nlumberjack:\n  filename: " + filename + "\nencoderConfig:\n  messageKey: MSG\n")
+
+	r := NewRegistry()
+	defer func() { _ = r.Shutdown(context.Background()) }()
+	option := NewConfigOption(WithData(data, "yaml"))
+	logger, err := r.GetLogger(option)
+	assert.Nil(t, err, "fail to create logger.")
+
+	// no entry is lost while the logger is rebuilt again and again.
+	const writers, count = 4, 200
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				logger.Info("entry")
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		_, err = r.GetLogger(cloneConfigOption(option, WithCreateNew(true)))
+		assert.Nil(t, err, "fail to create logger again.")
+	}
+	wg.Wait()
+	assert.Nil(t, r.Shutdown(context.Background()), "fail to shutdown.")
+
+	content, err := os.ReadFile(filename)
+	assert.Nil(t, err, "fail to read log file.")
+	assert.Equal(t, writers*count, strings.Count(string(content), `"MSG":"entry"`), "all entries should be written.")
+}
+
+// mustHolderOf returns the coreHolder used by the logger, it fails the test if there's none.
+func mustHolderOf(t *testing.T, logger *zap.Logger) *coreHolder {
+	holder, ok := holderOf(logger)
+	assert.True(t, ok, "the logger should be created by cfzap.")
+
+	return holder
+}
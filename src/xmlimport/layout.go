@@ -0,0 +1,366 @@
+package xmlimport
+
+import (
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// the default date format of the '%d' conversion without format, it's same in log4j2 and logback.
+const defaultDateFormat = "yyyy-MM-dd HH:mm:ss,SSS"
+
+// namedDateFormats are the named date formats of log4j2, the values starting from '%' are the encoders of cfzap.
+var namedDateFormats = map[string]string{
+	"DEFAULT":       defaultDateFormat,
+	"ISO8601":       "%iso8601",
+	"ISO8601_BASIC": "yyyyMMdd'T'HHmmss,SSS",
+	"ABSOLUTE":      "HH:mm:ss,SSS",
+	"COMPACT":       "yyyyMMddHHmmssSSS",
+	"DATE":          "dd MMM yyyy HH:mm:ss,SSS",
+	"UNIX":          "%epoch",
+	"UNIX_MILLIS":   "%millis",
+}
+
+// javaDateLayouts maps the letters of Java date format to the layouts of Go, by the number of repeated letters.
+// the last one is used when the letter is repeated more times.
+var javaDateLayouts = map[rune][]string{
+	'y': {"2006", "06", "06", "2006"},
+	'M': {"1", "01", "Jan", "January"},
+	'd': {"2", "02"},
+	'H': {"15", "15"},
+	'h': {"3", "03"},
+	'm': {"4", "04"},
+	's': {"5", "05"},
+	'S': {"0", "00", "000", "000000", "000000", "000000000"},
+	'a': {"PM"},
+	'E': {"Mon", "Mon", "Mon", "Monday"},
+	'z': {"MST"},
+	'Z': {"-0700"},
+	'X': {"Z07", "Z0700", "Z07:00"},
+}
+
+// the conversion words of the pattern layouts of log4j2 and logback, they are case sensitive.
+var (
+	timeWords      = []string{"d", "date"}
+	levelWords     = []string{"p", "level", "le"}
+	loggerWords    = []string{"c", "logger", "lo"}
+	messageWords   = []string{"m", "msg", "message", "mess"}
+	fileLineWords  = []string{"F", "file", "L", "line"}
+	locationWords  = []string{"l", "location", "caller"}
+	methodWords    = []string{"M", "method"}
+	exceptionWords = []string{"ex", "exception", "throwable", "xEx", "xException", "xThrowable", "rEx", "rException",
+		"rThrowable", "wEx", "wex", "nopex", "nopexception"}
+	threadWords     = []string{"t", "thread", "tn", "threadName", "T", "tid", "threadId", "tp", "threadPriority"}
+	contextWords    = []string{"X", "mdc", "MDC", "K", "map", "MAP", "x", "NDC", "ndc", "marker", "markerSimpleName"}
+	highlightWords  = []string{"highlight"}
+	decoratingWords = []string{"style", "clr", "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+		"gray", "boldRed", "boldGreen", "boldYellow", "boldBlue", "boldMagenta", "boldCyan", "boldWhite"}
+)
+
+// the JSON layouts, they are the encoderConfig sections of the JSON encoders.
+var (
+	// JsonLayout of log4j2.
+	log4j2JSONLayout = yaml.MapSlice{
+		{Key: "messageKey", Value: "message"},
+		{Key: "levelKey", Value: "level"},
+		{Key: "timeKey", Value: "timeMillis"},
+		{Key: "nameKey", Value: "loggerName"},
+		{Key: "stacktraceKey", Value: "thrown"},
+		{Key: "encodeLevel", Value: "capital"},
+		{Key: "encodeTime", Value: "millis"},
+	}
+	// EcsLayout of log4j2 and the Elastic Common Schema encoder of logback.
+	ecsJSONLayout = yaml.MapSlice{
+		{Key: "messageKey", Value: "message"},
+		{Key: "levelKey", Value: "log.level"},
+		{Key: "timeKey", Value: "@timestamp"},
+		{Key: "nameKey", Value: "log.logger"},
+		{Key: "stacktraceKey", Value: "error.stack_trace"},
+		{Key: "encodeLevel", Value: "capital"},
+		{Key: "encodeTime", Value: "iso8601"},
+	}
+	// LogstashEncoder of logback.
+	logstashJSONLayout = yaml.MapSlice{
+		{Key: "messageKey", Value: "message"},
+		{Key: "levelKey", Value: "level"},
+		{Key: "timeKey", Value: "@timestamp"},
+		{Key: "nameKey", Value: "logger_name"},
+		{Key: "stacktraceKey", Value: "stack_trace"},
+		{Key: "encodeLevel", Value: "capital"},
+		{Key: "encodeTime", Value: "iso8601"},
+	}
+)
+
+// patternTranslator translates a pattern layout into the settings of console encoder.
+type patternTranslator struct {
+	converter *converter
+	// the owner of the pattern in warnings, such as 'appender [console]'.
+	owner string
+	// the keys of encoderConfig section set by the conversions.
+	keys map[string]string
+	// true if any literal text other than white spaces is dropped.
+	dropped bool
+	// true if any literal text contains space.
+	spaced bool
+}
+
+// patternLayout translates the pattern layout of owner into the encoderConfig section of console encoder.
+// zap writes the fields in fixed order, so only the fields in the pattern and their formats are kept.
+// it returns the section, and true if the caller is written.
+func (c *converter) patternLayout(owner string, pattern string) (yaml.MapSlice, bool) {
+	t := &patternTranslator{converter: c, owner: owner, keys: make(map[string]string)}
+	t.translate(c.substitute(pattern))
+
+	if t.dropped {
+		c.warn("the literal text in the pattern of %s is dropped, the fields are written in the order of zap", owner)
+	}
+	if t.spaced {
+		t.keys["consoleSeparator"] = " "
+	}
+
+	section := yaml.MapSlice{}
+	for _, key := range []string{"messageKey", "levelKey", "timeKey", "nameKey", "callerKey", "functionKey",
+		"stacktraceKey", "consoleSeparator", "encodeLevel", "encodeTime", "encodeCaller"} {
+		if value, ok := t.keys[key]; ok {
+			section = append(section, yaml.MapItem{Key: key, Value: value})
+		}
+	}
+
+	_, caller := t.keys["callerKey"]
+
+	return section, caller
+}
+
+// translate translates the conversions in pattern.
+func (t *patternTranslator) translate(pattern string) {
+	for pattern != "" {
+		i := strings.IndexByte(pattern, '%')
+		if i < 0 {
+			t.literal(pattern)
+			return
+		}
+
+		t.literal(pattern[:i])
+		pattern = pattern[i+1:]
+
+		if strings.HasPrefix(pattern, "%") {
+			t.literal("%")
+			pattern = pattern[1:]
+			continue
+		}
+
+		// the format modifiers such as '-5' or '.-10' are skipped.
+		pattern = strings.TrimLeft(pattern, "-.0123456789")
+
+		end := strings.IndexFunc(pattern, func(r rune) bool { return !unicode.IsLetter(r) })
+		if end < 0 {
+			end = len(pattern)
+		}
+		word := pattern[:end]
+		pattern = pattern[end:]
+
+		// the conversions of logback can wrap a pattern in parentheses, such as '%red(%msg)'.
+		inner := ""
+		if strings.HasPrefix(pattern, "(") {
+			if end := closing(pattern, '(', ')'); end > 0 {
+				inner = pattern[1:end]
+				pattern = pattern[end+1:]
+			}
+		}
+
+		var options []string
+		for strings.HasPrefix(pattern, "{") {
+			end := closing(pattern, '{', '}')
+			if end < 0 {
+				break
+			}
+			options = append(options, pattern[1:end])
+			pattern = pattern[end+1:]
+		}
+
+		t.conversion(word, inner, options)
+	}
+}
+
+// literal records the literal text in the pattern.
+func (t *patternTranslator) literal(s string) {
+	if strings.Contains(s, " ") {
+		t.spaced = true
+	}
+	if strings.TrimSpace(s) != "" {
+		t.dropped = true
+	}
+}
+
+// conversion translates the conversion word, inner is the pattern wrapped in parentheses by logback.
+func (t *patternTranslator) conversion(word string, inner string, options []string) {
+	switch {
+	case word == "":
+		t.converter.warn("the empty conversion in the pattern of %s is skipped", t.owner)
+	case word == "n":
+		// the line ending of zap is '\n' too.
+	case contains(timeWords, word):
+		t.keys["timeKey"] = "time"
+		t.keys["encodeTime"] = t.dateFormat(options)
+	case contains(levelWords, word):
+		t.keys["levelKey"] = "level"
+		if _, ok := t.keys["encodeLevel"]; !ok {
+			// the levels of Java are in upper case.
+			t.keys["encodeLevel"] = "capital"
+		}
+	case contains(loggerWords, word):
+		t.keys["nameKey"] = "logger"
+	case contains(messageWords, word):
+		t.keys["messageKey"] = "msg"
+	case contains(fileLineWords, word):
+		t.keys["callerKey"] = "caller"
+		if _, ok := t.keys["encodeCaller"]; !ok {
+			t.keys["encodeCaller"] = "short"
+		}
+	case contains(locationWords, word):
+		t.keys["callerKey"] = "caller"
+		t.keys["encodeCaller"] = "full"
+	case contains(methodWords, word):
+		t.keys["functionKey"] = "func"
+	case contains(exceptionWords, word):
+		t.keys["stacktraceKey"] = "stacktrace"
+	case contains(threadWords, word):
+		t.converter.warn("the thread in the pattern of %s is not supported, it's skipped", t.owner)
+	case contains(contextWords, word):
+		t.converter.warn("the context data [%%%s] in the pattern of %s is not supported, it's skipped", word, t.owner)
+	case contains(highlightWords, word), contains(decoratingWords, word):
+		if contains(highlightWords, word) {
+			// zap colors the level only, and in lower case.
+			t.keys["encodeLevel"] = "color"
+		}
+		if inner == "" && len(options) > 0 {
+			// the conversions of log4j2 wrap a pattern in the first option, such as '%highlight{%p}'.
+			inner = options[0]
+		}
+		t.translate(inner)
+	default:
+		t.converter.warn("the conversion [%%%s] in the pattern of %s is not supported, it's skipped", word, t.owner)
+	}
+}
+
+// dateFormat returns the time encoder of cfzap translated from the options of the date conversion.
+func (t *patternTranslator) dateFormat(options []string) string {
+	format := defaultDateFormat
+	if len(options) > 0 && strings.TrimSpace(options[0]) != "" {
+		format = strings.TrimSpace(options[0])
+	}
+	if len(options) > 1 {
+		t.converter.warn("the time zone of the date in the pattern of %s is ignored", t.owner)
+	}
+
+	if named, ok := namedDateFormats[format]; ok {
+		if strings.HasPrefix(named, "%") {
+			return named[1:]
+		}
+		format = named
+	}
+
+	layout, ok := javaDateLayout(format)
+	if !ok {
+		t.converter.warn("the date format [%s] in the pattern of %s is not supported, ISO8601 is used", format, t.owner)
+		return "iso8601"
+	}
+
+	// the customized time format of cfzap starts from '%'.
+	return "%" + layout
+}
+
+// javaDateLayout translates the date format of Java, such as 'yyyy-MM-dd HH:mm:ss.SSS', into the layout of Go.
+// it returns false if the format contains any letter which has no counterpart in Go.
+func javaDateLayout(format string) (string, bool) {
+	var b strings.Builder
+	runes := []rune(format)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		// the text in single quotes is literal, and '' is a single quote both inside and outside the quotes.
+		if r == '\'' {
+			if i+1 < len(runes) && runes[i+1] == '\'' {
+				b.WriteRune('\'')
+				i += 2
+				continue
+			}
+
+			i++
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						b.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			continue
+		}
+
+		if !unicode.IsLetter(r) {
+			b.WriteRune(r)
+			i++
+			continue
+		}
+
+		count := 1
+		for i+count < len(runes) && runes[i+count] == r {
+			count++
+		}
+
+		layouts, ok := javaDateLayouts[r]
+		if !ok {
+			return "", false
+		}
+		if count > len(layouts) {
+			count = len(layouts)
+		}
+
+		// the fraction of second must follow '.' or ',' in Go.
+		if r == 'S' && (i == 0 || (runes[i-1] != '.' && runes[i-1] != ',')) {
+			return "", false
+		}
+
+		b.WriteString(layouts[count-1])
+		i += count
+	}
+
+	return b.String(), true
+}
+
+// closing returns the index of the bracket closing the one at the beginning of s, or -1 if it's not closed.
+func closing(s string, open byte, close byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// contains checks to see if the list contains the value, it's case sensitive.
+func contains(list []string, value string) bool {
+	for _, s := range list {
+		if s == value {
+			return true
+		}
+	}
+
+	return false
+}
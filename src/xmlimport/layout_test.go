@@ -0,0 +1,66 @@
+package xmlimport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestJavaDateLayout(t *testing.T) {
+	tests := map[string]string{
+		"yyyy-MM-dd HH:mm:ss,SSS":      "2006-01-02 15:04:05,000",
+		"yyyyMMdd'T'HHmmss.SSS":        "20060102T150405.000",
+		"dd MMM yyyy hh:mm a":          "02 Jan 2006 03:04 PM",
+		"EEE, d MMMM yy HH:mm:ss Z":    "Mon, 2 January 06 15:04:05 -0700",
+		"yyyy-MM-dd'T'HH:mm:ss.SSSXXX": "2006-01-02T15:04:05.000Z07:00",
+		"HH 'o''clock'":                "15 o'clock",
+	}
+	for format, expected := range tests {
+		layout, ok := javaDateLayout(format)
+		assert.True(t, ok, "format [%s] should be supported.", format)
+		assert.Equal(t, expected, layout)
+	}
+
+	// the week of year has no counterpart in Go, and the fraction must follow '.' or ','.
+	for _, format := range []string{"yyyy-ww", "HHmmssSSS"} {
+		_, ok := javaDateLayout(format)
+		assert.False(t, ok, "format [%s] should not be supported.", format)
+	}
+}
+
+func TestPatternLayout(t *testing.T) {
+	c := &converter{properties: map[string]string{}}
+
+	section, caller := c.patternLayout("appender [a]", "%date{ISO8601} %5p %c{1.}:%L %M - %m%n%xEx")
+	assert.True(t, caller, "the caller should be written.")
+	assert.Equal(t, yaml.MapSlice{
+		{Key: "messageKey", Value: "msg"},
+		{Key: "levelKey", Value: "level"},
+		{Key: "timeKey", Value: "time"},
+		{Key: "nameKey", Value: "logger"},
+		{Key: "callerKey", Value: "caller"},
+		{Key: "functionKey", Value: "func"},
+		{Key: "stacktraceKey", Value: "stacktrace"},
+		{Key: "consoleSeparator", Value: " "},
+		{Key: "encodeLevel", Value: "capital"},
+		{Key: "encodeTime", Value: "iso8601"},
+		{Key: "encodeCaller", Value: "short"},
+	}, section)
+
+	// the colors of logback wrap the pattern in parentheses.
+	c.warnings = nil
+	section, caller = c.patternLayout("appender [b]", "%d{UNIX_MILLIS}\t%highlight(%level) %cyan(%logger) %X{id} %m%n")
+	assert.False(t, caller, "the caller should not be written.")
+	assert.Equal(t, yaml.MapSlice{
+		{Key: "messageKey", Value: "msg"},
+		{Key: "levelKey", Value: "level"},
+		{Key: "timeKey", Value: "time"},
+		{Key: "nameKey", Value: "logger"},
+		{Key: "consoleSeparator", Value: " "},
+		{Key: "encodeLevel", Value: "color"},
+		{Key: "encodeTime", Value: "millis"},
+	}, section)
+	assert.Equal(t, []string{"the context data [%X] in the pattern of appender [b] is not supported, it's skipped"},
+		c.warnings)
+}
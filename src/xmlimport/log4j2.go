@@ -0,0 +1,229 @@
+package xmlimport
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// log4j2FileAppenders are the appenders of log4j2 writing to files.
+var log4j2FileAppenders = []string{"File", "RandomAccessFile", "MemoryMappedFile", "RollingFile",
+	"RollingRandomAccessFile"}
+
+// readLog4j2 reads the config of log4j2.xml.
+func (c *converter) readLog4j2(root *node) {
+	// the default level of root logger is 'error' in log4j2.
+	c.rootLevel = "error"
+
+	if properties := root.child("Properties"); properties != nil {
+		for _, p := range properties.children("Property") {
+			c.properties[p.attr("name")] = c.substitute(strings.TrimSpace(p.Text))
+		}
+	}
+
+	if appenders := root.child("Appenders"); appenders != nil {
+		for i := range appenders.Nodes {
+			c.readLog4j2Appender(&appenders.Nodes[i])
+		}
+	}
+
+	loggers := root.child("Loggers")
+	if loggers == nil {
+		return
+	}
+
+	for i := range loggers.Nodes {
+		logger := &loggers.Nodes[i]
+		kind := logger.XMLName.Local
+
+		switch {
+		case kind == "Root" || kind == "AsyncRoot":
+			if level := c.level("the root logger", logger.attr("level")); level != "" {
+				c.rootLevel = level
+			}
+			c.rootRefs = append(c.rootRefs, log4j2Refs(logger)...)
+		case kind == "Logger" || kind == "AsyncLogger":
+			name := c.substitute(logger.attr("name"))
+			owner := fmt.Sprintf("logger [%s]", name)
+			if level := c.level(owner, logger.attr("level")); level != "" {
+				c.levels = append(c.levels, namedLevel{name: name, level: level})
+			}
+			if len(log4j2Refs(logger)) > 0 {
+				c.warn("the appenders of %s are not converted, all loggers write to the appenders of the root logger",
+					owner)
+			}
+		default:
+			c.warn("element [%s] in [Loggers] is not supported, it's skipped", kind)
+		}
+	}
+}
+
+// readLog4j2Appender reads an appender in the 'Appenders' element.
+func (c *converter) readLog4j2Appender(n *node) {
+	kind := n.XMLName.Local
+	name := c.substitute(n.attr("name"))
+	owner := fmt.Sprintf("appender [%s]", name)
+
+	if kind == "Appender" {
+		c.warn("the appender of type [%s] in strict XML format is not supported, it's skipped", n.attr("type"))
+		return
+	}
+	if name == "" {
+		c.warn("the appender [%s] without name is skipped", kind)
+		return
+	}
+
+	a := &appender{name: name}
+
+	switch {
+	case kind == "Async":
+		c.addAsync(name, log4j2Refs(n))
+		return
+	case kind == "Console":
+		a.target = "stdout"
+		if strings.EqualFold(n.attr("target"), "SYSTEM_ERR") {
+			a.target = "stderr"
+		}
+	case contains(log4j2FileAppenders, kind):
+		a.target = "file"
+		if !c.readLog4j2File(n, owner, a) {
+			c.addAppender(name, nil)
+			return
+		}
+	default:
+		c.warn("%s of type [%s] is not supported, it's skipped", owner, kind)
+		c.addAppender(name, nil)
+		return
+	}
+
+	c.readLog4j2Layout(n, owner, a)
+	c.readLog4j2Filters(n, owner, a)
+	c.addAppender(name, a)
+}
+
+// readLog4j2File reads the file and rolling policies of the file appender.
+// it returns false if there's no file name.
+func (c *converter) readLog4j2File(n *node, owner string, a *appender) bool {
+	f := &rollingFile{converter: c, owner: owner, filename: c.substitute(n.attr("fileName"))}
+	if f.filename == "" {
+		c.warn("%s has no file name, it's skipped", owner)
+		return false
+	}
+
+	if pattern := n.attr("filePattern"); pattern != "" {
+		f.setPattern(pattern)
+	}
+
+	if policies := n.child("Policies"); policies != nil {
+		for i := range policies.Nodes {
+			c.readLog4j2Policy(&policies.Nodes[i], f)
+		}
+	} else if policy := n.child("SizeBasedTriggeringPolicy"); policy != nil {
+		c.readLog4j2Policy(policy, f)
+	} else if policy := n.child("TimeBasedTriggeringPolicy"); policy != nil {
+		c.readLog4j2Policy(policy, f)
+	}
+
+	if strategy := n.child("DefaultRolloverStrategy"); strategy != nil {
+		if max := strategy.attr("max"); max != "" {
+			f.setMaxBackups(max)
+		}
+		for _, del := range strategy.children("Delete") {
+			if modified := del.child("IfLastModified"); modified != nil {
+				f.setMaxAge(modified.attr("age"))
+			} else {
+				c.warn("the delete action of %s is not supported except [IfLastModified]", owner)
+			}
+		}
+	}
+
+	a.file = f.section()
+
+	return true
+}
+
+// readLog4j2Policy reads a triggering policy of the rolling file.
+func (c *converter) readLog4j2Policy(policy *node, f *rollingFile) {
+	switch kind := policy.XMLName.Local; kind {
+	case "SizeBasedTriggeringPolicy":
+		f.setMaxSize(policy.attr("size"))
+	case "TimeBasedTriggeringPolicy", "CronTriggeringPolicy":
+		c.warn("[%s] of %s is not supported, the file is rolled by size only", kind, f.owner)
+	case "OnStartupTriggeringPolicy":
+		c.warn("[%s] of %s is not supported, the file is not rolled on startup", kind, f.owner)
+	default:
+		c.warn("[%s] of %s is not supported, it's skipped", kind, f.owner)
+	}
+}
+
+// readLog4j2Layout reads the layout of the appender.
+func (c *converter) readLog4j2Layout(n *node, owner string, a *appender) {
+	for i := range n.Nodes {
+		layout := &n.Nodes[i]
+
+		switch kind := layout.XMLName.Local; kind {
+		case "PatternLayout":
+			a.encoderType = "console"
+			a.encoder, a.caller = c.patternLayout(owner, layout.attr("pattern"))
+			return
+		case "JsonLayout", "JSONLayout":
+			a.encoderType = "json"
+			a.encoder = log4j2JSONLayout
+			if strings.EqualFold(layout.attr("locationInfo"), "true") {
+				a.encoder = append(append(yaml.MapSlice{}, a.encoder...), yaml.MapItem{Key: "callerKey", Value: "source"})
+				a.caller = true
+			}
+			return
+		case "EcsLayout", "JsonTemplateLayout":
+			if kind == "JsonTemplateLayout" && layout.attr("eventTemplateUri") != "" {
+				c.warn("the event template of %s is not supported, the ECS layout is used", owner)
+			}
+			a.encoderType = "json"
+			a.encoder = ecsJSONLayout
+			return
+		default:
+			if strings.HasSuffix(kind, "Layout") {
+				c.warn("[%s] of %s is not supported, the message is written only", kind, owner)
+				a.encoderType = "console"
+				a.encoder, a.caller = c.patternLayout(owner, "%m%n")
+				return
+			}
+		}
+	}
+
+	// the default layout of log4j2 is '%m%n'.
+	a.encoderType = "console"
+	a.encoder, a.caller = c.patternLayout(owner, "%m%n")
+}
+
+// readLog4j2Filters reads the threshold filter of the appender, the other filters are not supported.
+func (c *converter) readLog4j2Filters(n *node, owner string, a *appender) {
+	filters := []*node{n}
+	if f := n.child("Filters"); f != nil {
+		filters = append(filters, f)
+	}
+
+	for _, parent := range filters {
+		for i := range parent.Nodes {
+			filter := &parent.Nodes[i]
+
+			switch kind := filter.XMLName.Local; {
+			case kind == "ThresholdFilter":
+				a.threshold = c.level(owner, filter.attr("level"))
+			case strings.HasSuffix(kind, "Filter"):
+				c.warn("[%s] of %s is not supported, it's skipped", kind, owner)
+			}
+		}
+	}
+}
+
+// log4j2Refs returns the names of the appenders referred by the element.
+func log4j2Refs(n *node) []string {
+	var refs []string
+	for _, ref := range n.children("AppenderRef") {
+		refs = append(refs, ref.attr("ref"))
+	}
+
+	return refs
+}
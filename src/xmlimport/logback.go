@@ -0,0 +1,233 @@
+package xmlimport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// readLogback reads the config of logback.xml.
+func (c *converter) readLogback(root *node) {
+	// the default level of root logger is 'debug' in logback.
+	c.rootLevel = "debug"
+
+	for i := range root.Nodes {
+		n := &root.Nodes[i]
+
+		switch kind := n.XMLName.Local; kind {
+		case "property", "variable":
+			if file := n.attr("file"); file != "" {
+				c.warn("the properties in file [%s] are not loaded", file)
+			}
+			if resource := n.attr("resource"); resource != "" {
+				c.warn("the properties in resource [%s] are not loaded", resource)
+			}
+			if name := n.attr("name"); name != "" {
+				c.properties[name] = c.substitute(n.attr("value"))
+			}
+		case "appender":
+			c.readLogbackAppender(n)
+		case "root":
+			if level := c.level("the root logger", logbackLevel(n)); level != "" {
+				c.rootLevel = level
+			}
+			c.rootRefs = append(c.rootRefs, logbackRefs(n)...)
+		case "logger":
+			name := c.substitute(n.attr("name"))
+			owner := fmt.Sprintf("logger [%s]", name)
+			if level := c.level(owner, logbackLevel(n)); level != "" {
+				c.levels = append(c.levels, namedLevel{name: name, level: level})
+			}
+			if len(logbackRefs(n)) > 0 {
+				c.warn("the appenders of %s are not converted, all loggers write to the appenders of the root logger",
+					owner)
+			}
+		case "include", "springProfile", "if":
+			c.warn("element [%s] is not supported, it's skipped", kind)
+		}
+	}
+}
+
+// readLogbackAppender reads an appender element.
+func (c *converter) readLogbackAppender(n *node) {
+	name := c.substitute(n.attr("name"))
+	class := n.attr("class")
+	owner := fmt.Sprintf("appender [%s]", name)
+
+	if name == "" {
+		c.warn("the appender of class [%s] without name is skipped", class)
+		return
+	}
+
+	a := &appender{name: name}
+
+	switch {
+	case strings.HasSuffix(class, "AsyncAppender") || strings.HasSuffix(class, "AsyncDisruptorAppender"):
+		c.addAsync(name, logbackRefs(n))
+		return
+	case strings.HasSuffix(class, ".ConsoleAppender"):
+		a.target = "stdout"
+		if strings.EqualFold(c.substitute(n.childText("target")), "System.err") {
+			a.target = "stderr"
+		}
+	case strings.HasSuffix(class, ".FileAppender") || strings.HasSuffix(class, ".RollingFileAppender"):
+		a.target = "file"
+		if !c.readLogbackFile(n, owner, a) {
+			c.addAppender(name, nil)
+			return
+		}
+	default:
+		c.warn("%s of class [%s] is not supported, it's skipped", owner, class)
+		c.addAppender(name, nil)
+		return
+	}
+
+	c.readLogbackEncoder(n, owner, a)
+	c.readLogbackFilters(n, owner, a)
+	c.addAppender(name, a)
+}
+
+// readLogbackFile reads the file and rolling policies of the file appender.
+// it returns false if there's no file name.
+func (c *converter) readLogbackFile(n *node, owner string, a *appender) bool {
+	f := &rollingFile{converter: c, owner: owner, filename: c.substitute(n.childText("file"))}
+	if f.filename == "" {
+		c.warn("%s has no file name, it's skipped", owner)
+		return false
+	}
+
+	if policy := n.child("rollingPolicy"); policy != nil {
+		class := policy.attr("class")
+		pattern := policy.childText("fileNamePattern")
+		f.setPattern(pattern)
+
+		if size := policy.childText("maxFileSize"); size != "" {
+			f.setMaxSize(size)
+		}
+		if index := policy.childText("maxIndex"); index != "" {
+			f.setMaxBackups(index)
+		}
+		if history := policy.childText("maxHistory"); history != "" {
+			// the history is counted in the rolling periods, they are days only for daily rolling.
+			if isDailyPattern(pattern) {
+				f.setMaxAge(history + "d")
+			} else {
+				c.warn("the max history of %s is not daily, it's ignored", owner)
+			}
+		}
+		if policy.child("totalSizeCap") != nil {
+			c.warn("the total size cap of %s is not supported, it's ignored", owner)
+		}
+		if strings.HasSuffix(class, "TimeBasedRollingPolicy") && policy.childText("maxFileSize") == "" {
+			c.warn("[%s] of %s is not supported, the file is rolled by size only", class, owner)
+		}
+	}
+
+	if policy := n.child("triggeringPolicy"); policy != nil {
+		if size := policy.childText("maxFileSize"); size != "" {
+			f.setMaxSize(size)
+		} else {
+			c.warn("[%s] of %s is not supported, it's skipped", policy.attr("class"), owner)
+		}
+	}
+
+	a.file = f.section()
+
+	return true
+}
+
+// readLogbackEncoder reads the encoder or layout of the appender.
+func (c *converter) readLogbackEncoder(n *node, owner string, a *appender) {
+	encoder := n.child("encoder")
+	if encoder == nil {
+		encoder = n.child("layout")
+	}
+	if encoder == nil {
+		c.warn("%s has no encoder, the message is written only", owner)
+		a.encoderType = "console"
+		a.encoder, a.caller = c.patternLayout(owner, "%m%n")
+		return
+	}
+
+	class := encoder.attr("class")
+	switch {
+	case strings.Contains(class, "Logstash"):
+		a.encoderType = "json"
+		a.encoder = logstashJSONLayout
+	case strings.Contains(class, "Ecs"):
+		a.encoderType = "json"
+		a.encoder = ecsJSONLayout
+	case strings.Contains(class, "Json") || strings.Contains(class, "JSON"):
+		c.warn("[%s] of %s is converted to the logstash JSON format", class, owner)
+		a.encoderType = "json"
+		a.encoder = logstashJSONLayout
+	default:
+		pattern := encoder.childText("pattern")
+		if pattern == "" {
+			if layout := encoder.child("layout"); layout != nil {
+				pattern = layout.childText("pattern")
+			}
+		}
+		if pattern == "" {
+			c.warn("%s has no pattern, the message is written only", owner)
+			pattern = "%m%n"
+		}
+
+		a.encoderType = "console"
+		a.encoder, a.caller = c.patternLayout(owner, pattern)
+	}
+}
+
+// readLogbackFilters reads the threshold filter of the appender, the other filters are not supported.
+func (c *converter) readLogbackFilters(n *node, owner string, a *appender) {
+	for _, filter := range n.children("filter") {
+		if class := filter.attr("class"); strings.HasSuffix(class, ".ThresholdFilter") {
+			a.threshold = c.level(owner, filter.childText("level"))
+		} else {
+			c.warn("[%s] of %s is not supported, it's skipped", class, owner)
+		}
+	}
+}
+
+// logbackLevel returns the level of the logger, it's either an attribute or a child element.
+func logbackLevel(n *node) string {
+	if level := n.attr("level"); level != "" {
+		return level
+	}
+	if level := n.child("level"); level != nil {
+		return level.attr("value")
+	}
+
+	return ""
+}
+
+// logbackRefs returns the names of the appenders referred by the element.
+func logbackRefs(n *node) []string {
+	var refs []string
+	for _, ref := range n.children("appender-ref") {
+		refs = append(refs, ref.attr("ref"))
+	}
+
+	return refs
+}
+
+// isDailyPattern checks to see if the file name pattern rolls the file daily, such as 'app-%d{yyyy-MM-dd}.log'.
+func isDailyPattern(pattern string) bool {
+	i := strings.Index(pattern, "%d")
+	if i < 0 {
+		return false
+	}
+
+	rest := pattern[i+2:]
+	if !strings.HasPrefix(rest, "{") {
+		// the default date format is 'yyyy-MM-dd'.
+		return true
+	}
+
+	end := strings.Index(rest, "}")
+	if end < 0 {
+		return false
+	}
+
+	format := strings.SplitN(rest[1:end], ",", 2)[0]
+	return strings.Contains(format, "d") && !strings.ContainsAny(format, "Hhms")
+}
@@ -0,0 +1,188 @@
+package xmlimport
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// zapLevels are the levels of zap used in cfzap config, from low to high.
+var zapLevels = []string{"debug", "info", "warn", "error", "fatal"}
+
+// javaLevels maps the levels of log4j2 and logback, in lower case, to the levels of zap.
+var javaLevels = map[string]string{
+	"all":   "debug",
+	"trace": "debug",
+	"debug": "debug",
+	"info":  "info",
+	"warn":  "warn",
+	"error": "error",
+	"fatal": "fatal",
+	"off":   "fatal",
+}
+
+// level returns the level of zap converted from the Java level of owner, such as 'logger [com.foo]'.
+// it returns empty string if value is empty or unknown.
+func (c *converter) level(owner string, value string) string {
+	value = strings.ToLower(strings.TrimSpace(c.substitute(value)))
+	if value == "" {
+		return ""
+	}
+
+	level, ok := javaLevels[value]
+	if !ok {
+		c.warn("level [%s] of %s is unknown, it's ignored", value, owner)
+		return ""
+	}
+	if value == "off" {
+		c.warn("level [off] of %s is converted to [fatal]", owner)
+	}
+
+	return level
+}
+
+// higherLevel returns the higher one of the zap levels, the empty level is lower than all levels.
+// it returns 'info' if both are empty.
+func higherLevel(a string, b string) string {
+	index := func(level string) int {
+		for i, l := range zapLevels {
+			if l == level {
+				return i
+			}
+		}
+		return -1
+	}
+
+	if index(a) < 0 && index(b) < 0 {
+		return "info"
+	}
+	if index(a) >= index(b) {
+		return a
+	}
+
+	return b
+}
+
+// rollingFile builds the lumberjack section of a file appender.
+type rollingFile struct {
+	converter *converter
+	// the owner of the rolling policies in warnings, such as 'appender [file]'.
+	owner    string
+	filename string
+	maxSize  int
+	maxAge   int
+	backups  int
+	compress bool
+}
+
+// setMaxSize sets the max size of the file from the size such as '250 MB' or '10MB'.
+func (f *rollingFile) setMaxSize(size string) {
+	if mb, ok := parseMegabytes(f.converter.substitute(size)); ok {
+		f.maxSize = mb
+	} else {
+		f.converter.warn("size [%s] of %s is invalid, it's ignored", size, f.owner)
+	}
+}
+
+// setMaxAge sets the max age of the file from the age such as '30d' or 'P30D'.
+func (f *rollingFile) setMaxAge(age string) {
+	if days, ok := parseDays(f.converter.substitute(age)); ok {
+		f.maxAge = days
+	} else {
+		f.converter.warn("age [%s] of %s is not in days, it's ignored", age, f.owner)
+	}
+}
+
+// setMaxBackups sets the max number of the old files from the number such as '20'.
+func (f *rollingFile) setMaxBackups(count string) {
+	if n, err := strconv.Atoi(strings.TrimSpace(f.converter.substitute(count))); err == nil && n >= 0 {
+		f.backups = n
+	} else {
+		f.converter.warn("max files [%s] of %s is invalid, it's ignored", count, f.owner)
+	}
+}
+
+// setPattern sets the compression from the file pattern of the old files, such as 'app-%d{yyyy-MM-dd}.log.gz'.
+func (f *rollingFile) setPattern(pattern string) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+	switch {
+	case strings.HasSuffix(pattern, ".gz"):
+		f.compress = true
+	case strings.HasSuffix(pattern, ".zip"), strings.HasSuffix(pattern, ".bz2"), strings.HasSuffix(pattern, ".xz"),
+		strings.HasSuffix(pattern, ".deflate"), strings.HasSuffix(pattern, ".pack200"):
+		f.compress = true
+		f.converter.warn("the old files of %s are compressed by gzip instead", f.owner)
+	}
+}
+
+// section returns the lumberjack section.
+func (f *rollingFile) section() yaml.MapSlice {
+	section := yaml.MapSlice{{Key: "filename", Value: f.filename}}
+
+	if f.maxSize > 0 {
+		section = append(section, yaml.MapItem{Key: "maxSize", Value: f.maxSize})
+	}
+	if f.maxAge > 0 {
+		section = append(section, yaml.MapItem{Key: "maxAge", Value: f.maxAge})
+	}
+	if f.backups > 0 {
+		section = append(section, yaml.MapItem{Key: "maxBackups", Value: f.backups})
+	}
+
+	return append(section,
+		yaml.MapItem{Key: "localTime", Value: true},
+		yaml.MapItem{Key: "compress", Value: f.compress})
+}
+
+// parseMegabytes parses the size such as '250 MB', '10MB', '1 GB' or '1048576' into megabytes, rounded up.
+func parseMegabytes(size string) (int, bool) {
+	size = strings.ToLower(strings.TrimSpace(size))
+
+	i := strings.IndexFunc(size, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+	number, unit := size, ""
+	if i >= 0 {
+		number, unit = size[:i], strings.TrimSpace(size[i:])
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+
+	var total float64
+	switch unit {
+	case "", "b", "bytes":
+		total = value
+	case "k", "kb":
+		total = value * 1024
+	case "m", "mb":
+		total = value * 1024 * 1024
+	case "g", "gb":
+		total = value * 1024 * 1024 * 1024
+	default:
+		return 0, false
+	}
+
+	return int(math.Ceil(total / (1024 * 1024))), true
+}
+
+// parseDays parses the age such as '30d' of log4j2 or the ISO-8601 duration such as 'P30D' into days.
+func parseDays(age string) (int, bool) {
+	age = strings.ToUpper(strings.TrimSpace(age))
+	age = strings.TrimPrefix(age, "P")
+
+	if !strings.HasSuffix(age, "D") {
+		return 0, false
+	}
+
+	days, err := strconv.Atoi(strings.TrimSuffix(age, "D"))
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+
+	return days, true
+}
@@ -0,0 +1,46 @@
+package xmlimport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMegabytes(t *testing.T) {
+	tests := map[string]int{"250 MB": 250, "10mb": 10, "1 GB": 1024, "500KB": 1, "1048576": 1, "1.5 MB": 2}
+	for size, expected := range tests {
+		mb, ok := parseMegabytes(size)
+		assert.True(t, ok, "size [%s] should be valid.", size)
+		assert.Equal(t, expected, mb, "size [%s]", size)
+	}
+
+	for _, size := range []string{"", "MB", "-1 MB", "10 TB"} {
+		_, ok := parseMegabytes(size)
+		assert.False(t, ok, "size [%s] should be invalid.", size)
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	for age, expected := range map[string]int{"30d": 30, "P7D": 7, " 1D ": 1} {
+		days, ok := parseDays(age)
+		assert.True(t, ok, "age [%s] should be valid.", age)
+		assert.Equal(t, expected, days)
+	}
+
+	for _, age := range []string{"12h", "PT12H", "0d", "d"} {
+		_, ok := parseDays(age)
+		assert.False(t, ok, "age [%s] should be invalid.", age)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	assert.Equal(t, "warn", higherLevel("info", "warn"))
+	assert.Equal(t, "error", higherLevel("error", "debug"))
+	assert.Equal(t, "debug", higherLevel("", "debug"))
+	assert.Equal(t, "info", higherLevel("", ""))
+
+	assert.True(t, isDailyPattern("app-%d.log"))
+	assert.True(t, isDailyPattern("app-%d{yyyy-MM-dd}.%i.log.gz"))
+	assert.False(t, isDailyPattern("app-%d{yyyy-MM-dd_HH}.log"))
+	assert.False(t, isDailyPattern("app.%i.log"))
+}
@@ -0,0 +1,381 @@
+// Package xmlimport converts the logging config of Java services, log4j2.xml and logback.xml,
+// into a cfzap config in YAML.
+//
+// The appenders referred by the root logger become the cfzap appenders. Console appenders write to 'stdout' or
+// 'stderr', file appenders write to lumberjack targets whose rolling fields are translated from the rolling
+// policies, and the layouts become encoderConfig sections: the pattern layouts are translated into console encoder
+// settings where possible, and the JSON layouts into JSON encoder settings. The levels of the other loggers
+// become the 'levels' section, as they are named by logger names in both worlds.
+//
+// The constructs which have no counterpart in cfzap, such as time based rolling or thread names in patterns,
+// are skipped and reported as warnings.
+package xmlimport
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The formats of the XML config.
+const (
+	// FormatLog4j2 is the format of log4j2.xml, its root element is 'Configuration'.
+	FormatLog4j2 = "log4j2"
+	// FormatLogback is the format of logback.xml, its root element is 'configuration'.
+	FormatLogback = "logback"
+)
+
+// Result is the result of converting.
+type Result struct {
+	// Format is FormatLog4j2 or FormatLogback.
+	Format string
+	// Config is the cfzap config in YAML, the warnings are listed in its header comment too.
+	Config []byte
+	// Warnings lists the constructs which are not converted or converted approximately.
+	Warnings []string
+}
+
+// Convert converts the content of log4j2.xml or logback.xml into a cfzap config in YAML.
+// The format is detected by the root element.
+// It returns error if the XML is malformed, or no appender can be converted.
+func Convert(data []byte) (*Result, error) {
+	var root node
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	c := &converter{properties: make(map[string]string)}
+
+	switch root.XMLName.Local {
+	case "Configuration":
+		c.format = FormatLog4j2
+		c.readLog4j2(&root)
+	case "configuration":
+		c.format = FormatLogback
+		c.readLogback(&root)
+	default:
+		return nil, fmt.Errorf("unknown root element [%s], it should be [Configuration] of log4j2 "+
+			"or [configuration] of logback", root.XMLName.Local)
+	}
+
+	config, err := c.yaml()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Format: c.format, Config: config, Warnings: c.warnings}, nil
+}
+
+// ConvertFile converts the file log4j2.xml or logback.xml into a cfzap config in YAML. See Convert() for details.
+func ConvertFile(filename string) (*Result, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Convert(data)
+}
+
+// node is an element of XML.
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []node     `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// attr returns the value of the attribute, the name is not case sensitive. it's empty if there's no such attribute.
+func (n *node) attr(name string) string {
+	for _, a := range n.Attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+
+	return ""
+}
+
+// child returns the first child element of the name, the name is not case sensitive.
+// it returns nil if there's no such element.
+func (n *node) child(name string) *node {
+	for i := range n.Nodes {
+		if strings.EqualFold(n.Nodes[i].XMLName.Local, name) {
+			return &n.Nodes[i]
+		}
+	}
+
+	return nil
+}
+
+// children returns all child elements of the name, the name is not case sensitive.
+func (n *node) children(name string) []*node {
+	var nodes []*node
+	for i := range n.Nodes {
+		if strings.EqualFold(n.Nodes[i].XMLName.Local, name) {
+			nodes = append(nodes, &n.Nodes[i])
+		}
+	}
+
+	return nodes
+}
+
+// childText returns the trimmed text of the first child element of the name, it's empty if there's no such element.
+func (n *node) childText(name string) string {
+	if child := n.child(name); child != nil {
+		return strings.TrimSpace(child.Text)
+	}
+
+	return ""
+}
+
+// appender is an appender converted from XML.
+type appender struct {
+	name string
+	// 'stdout', 'stderr' or 'file'.
+	target string
+	// the lumberjack section when target is 'file'.
+	file yaml.MapSlice
+	// the level of the threshold filter, it's empty if there's no such filter.
+	threshold string
+	// 'console' or 'json'.
+	encoderType string
+	// the encoderConfig section.
+	encoder yaml.MapSlice
+	// true if the layout outputs the caller.
+	caller bool
+}
+
+// namedLevel is the level of a logger name.
+type namedLevel struct {
+	name  string
+	level string
+}
+
+// converter holds the state of converting.
+type converter struct {
+	format string
+	// the properties defined in XML, they are substituted in all values.
+	properties map[string]string
+	// the appenders defined in XML by name, nil for the appenders which cannot be converted.
+	appenders map[string]*appender
+	// the appender names in XML order.
+	order []string
+	// the appenders referred by the async appenders.
+	async map[string][]string
+	// the appenders referred by the root logger.
+	rootRefs []string
+	// the level of the root logger.
+	rootLevel string
+	// the levels of the other loggers.
+	levels   []namedLevel
+	warnings []string
+}
+
+// warn adds a warning.
+func (c *converter) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// addAppender adds the appender, it's nil if the appender cannot be converted.
+func (c *converter) addAppender(name string, a *appender) {
+	if c.appenders == nil {
+		c.appenders = make(map[string]*appender)
+	}
+
+	if _, ok := c.appenders[name]; ok {
+		c.warn("appender [%s] is defined more than once, the first one is used", name)
+		return
+	}
+
+	c.appenders[name] = a
+	c.order = append(c.order, name)
+}
+
+// addAsync adds the async appender which writes to the refs.
+func (c *converter) addAsync(name string, refs []string) {
+	if c.async == nil {
+		c.async = make(map[string][]string)
+	}
+
+	c.async[name] = refs
+	c.addAppender(name, nil)
+	c.warn("async appender [%s] is replaced by the appenders it refers to", name)
+}
+
+// resolveRefs returns the appenders written by the refs, the async appenders are replaced by their refs.
+func (c *converter) resolveRefs(refs []string, seen map[string]bool) []*appender {
+	var appenders []*appender
+
+	for _, ref := range refs {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		if async, ok := c.async[ref]; ok {
+			appenders = append(appenders, c.resolveRefs(async, seen)...)
+			continue
+		}
+
+		a, ok := c.appenders[ref]
+		if !ok {
+			c.warn("appender [%s] is referred but not defined", ref)
+		} else if a != nil {
+			appenders = append(appenders, a)
+		}
+	}
+
+	return appenders
+}
+
+// yaml returns the cfzap config in YAML.
+func (c *converter) yaml() ([]byte, error) {
+	appenders := c.resolveRefs(c.rootRefs, make(map[string]bool))
+	if len(appenders) == 0 {
+		return nil, fmt.Errorf("no appender referred by the root logger can be converted")
+	}
+
+	used := make(map[string]bool)
+	for _, a := range appenders {
+		used[a.name] = true
+	}
+	for _, name := range c.order {
+		if a := c.appenders[name]; a != nil && !used[name] {
+			c.warn("appender [%s] is not referred by the root logger, it's skipped", name)
+		}
+	}
+
+	settings := yaml.MapSlice{}
+
+	names := make([]string, len(appenders))
+	caller := false
+	for i, a := range appenders {
+		names[i] = a.name
+		caller = caller || a.caller
+	}
+	settings = append(settings, yaml.MapItem{Key: "appenders", Value: names})
+
+	if caller {
+		settings = append(settings, yaml.MapItem{Key: "options", Value: yaml.MapSlice{{Key: "caller", Value: true}}})
+	}
+
+	if len(c.levels) > 0 {
+		levels := yaml.MapSlice{}
+		for _, l := range c.levels {
+			levels = append(levels, yaml.MapItem{Key: l.name, Value: l.level})
+		}
+		settings = append(settings, yaml.MapItem{Key: "levels", Value: levels})
+	}
+
+	for _, a := range appenders {
+		section := yaml.MapSlice{}
+		target := a.target
+		if a.target == "file" {
+			target = a.name + "-lumberjack"
+		}
+		section = append(section,
+			yaml.MapItem{Key: "target", Value: target},
+			yaml.MapItem{Key: "encoderType", Value: a.encoderType},
+			yaml.MapItem{Key: "logLevel", Value: higherLevel(c.rootLevel, a.threshold)})
+		// the threshold filter drops the entries below it, whatever the levels of the loggers.
+		if a.threshold != "" {
+			section = append(section, yaml.MapItem{Key: "threshold", Value: a.threshold})
+		}
+		section = append(section, yaml.MapItem{Key: "encoderConfig", Value: a.name + "-encoder"})
+		settings = append(settings, yaml.MapItem{Key: a.name, Value: section})
+
+		if a.target == "file" {
+			settings = append(settings, yaml.MapItem{Key: target, Value: a.file})
+		}
+		settings = append(settings, yaml.MapItem{Key: a.name + "-encoder", Value: a.encoder})
+	}
+
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# converted from " + c.format + " XML config.\n")
+	if len(c.warnings) > 0 {
+		b.WriteString("# warnings:\n")
+		for _, w := range c.warnings {
+			b.WriteString("#   - " + w + "\n")
+		}
+	}
+	b.Write(data)
+
+	return b.Bytes(), nil
+}
+
+// substitute replaces the properties in s with their values, see variable() for the syntax.
+func (c *converter) substitute(s string) string {
+	var b strings.Builder
+
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+
+		end := strings.Index(s[i:], "}")
+		if end < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end += i
+
+		b.WriteString(s[:i])
+		b.WriteString(c.variable(s[i+2 : end]))
+		s = s[end+1:]
+	}
+}
+
+// variable returns the value of the variable expression inside '${' and '}'.
+// the properties defined in XML are replaced by their values. the others, such as '${env:HOME}' of log4j2
+// or '${HOME}' of logback, are taken as environment variables and written as '${HOME}' of cfzap.
+// the default value written as '${NAME:-default}' is kept.
+// the other lookups, such as '${ctx:user}', cannot be resolved by cfzap, so the default value is used if any,
+// otherwise the expression is written as the literal text '$${ctx:user}'.
+func (c *converter) variable(expression string) string {
+	name := expression
+	defaultValue := ""
+	hasDefault := false
+	if i := strings.Index(expression, ":-"); i >= 0 {
+		name = expression[:i]
+		defaultValue = expression[i+2:]
+		hasDefault = true
+	}
+
+	if value, ok := c.properties[name]; ok {
+		return value
+	}
+
+	for _, prefix := range []string{"env:", "sys:"} {
+		if strings.HasPrefix(name, prefix) {
+			if prefix == "sys:" {
+				c.warn("system property [%s] is taken as an environment variable", name[len(prefix):])
+			}
+			name = name[len(prefix):]
+		}
+	}
+	if strings.Contains(name, ":") {
+		if hasDefault {
+			c.warn("lookup [${%s}] is not supported, its default value is used", expression)
+			return defaultValue
+		}
+		c.warn("lookup [${%s}] is not supported, it's written as literal text", expression)
+		return "$${" + expression + "}"
+	}
+
+	if hasDefault {
+		return "${" + name + ":-" + defaultValue + "}"
+	}
+
+	return "${" + name + "}"
+}
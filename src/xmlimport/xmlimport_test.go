@@ -0,0 +1,130 @@
+package xmlimport
+
+import (
+	"bytes"
+	"testing"
+
+	cfzap "cfzap/src"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFilePath = "../test_config_file/"
+
+// readResult reads the converted config, and checks it against the rules of cfzap.
+func readResult(t *testing.T, result *Result) *viper.Viper {
+	assert.Nil(t, cfzap.Validate(cfzap.NewConfigOption(cfzap.WithData(result.Config, "yaml"))),
+		"the converted config should be valid.")
+
+	config := viper.New()
+	config.SetConfigType("yaml")
+	assert.Nil(t, config.ReadConfig(bytes.NewReader(result.Config)), "the converted config should be yaml.")
+
+	return config
+}
+
+func TestConvertLog4j2(t *testing.T) {
+	result, err := ConvertFile(testFilePath + "log4j2.xml")
+	assert.Nil(t, err, "fail to convert log4j2.xml.")
+	assert.Equal(t, FormatLog4j2, result.Format)
+
+	config := readResult(t, result)
+	assert.Equal(t, []interface{}{"Console", "RollingFile"}, config.Get("appenders"))
+	assert.Equal(t, "debug", config.GetString("levels.com.example.db"))
+	assert.Equal(t, "warn", config.GetString("levels.org.apache"))
+
+	assert.Equal(t, "stdout", config.GetString("Console.target"))
+	assert.Equal(t, "console", config.GetString("Console.encoderType"))
+	assert.Equal(t, "info", config.GetString("Console.logLevel"))
+	assert.Equal(t, "color", config.GetString("Console-encoder.encodeLevel"))
+	assert.Equal(t, "%2006-01-02 15:04:05.000", config.GetString("Console-encoder.encodeTime"))
+	assert.Equal(t, " ", config.GetString("Console-encoder.consoleSeparator"))
+
+	// the threshold filter is higher than the root level.
+	assert.Equal(t, "warn", config.GetString("RollingFile.logLevel"))
+	assert.Equal(t, "warn", config.GetString("RollingFile.threshold"), "the threshold filter applies to all loggers")
+	assert.False(t, config.IsSet("Console.threshold"), "no threshold without the threshold filter")
+	assert.Equal(t, "json", config.GetString("RollingFile.encoderType"))
+	assert.Equal(t, "../logs/app.log", config.GetString("RollingFile-lumberjack.filename"))
+	assert.Equal(t, 250, config.GetInt("RollingFile-lumberjack.maxSize"))
+	assert.Equal(t, 30, config.GetInt("RollingFile-lumberjack.maxAge"))
+	assert.Equal(t, 20, config.GetInt("RollingFile-lumberjack.maxBackups"))
+	assert.True(t, config.GetBool("RollingFile-lumberjack.compress"))
+
+	assert.Equal(t, []string{
+		"the thread in the pattern of appender [Console] is not supported, it's skipped",
+		"the literal text in the pattern of appender [Console] is dropped, the fields are written in the order of zap",
+		"[TimeBasedTriggeringPolicy] of appender [RollingFile] is not supported, the file is rolled by size only",
+		"async appender [Async] is replaced by the appenders it refers to",
+		"appender [Socket] of type [Socket] is not supported, it's skipped",
+		"the appenders of logger [org.apache] are not converted, all loggers write to the appenders of the root logger",
+	}, result.Warnings)
+}
+
+func TestConvertLogback(t *testing.T) {
+	result, err := ConvertFile(testFilePath + "logback.xml")
+	assert.Nil(t, err, "fail to convert logback.xml.")
+	assert.Equal(t, FormatLogback, result.Format)
+
+	config := readResult(t, result)
+	assert.Equal(t, []interface{}{"STDERR", "FILE"}, config.Get("appenders"))
+	assert.True(t, config.GetBool("options.caller"))
+	assert.Equal(t, "debug", config.GetString("levels.com.example"))
+
+	assert.Equal(t, "stderr", config.GetString("STDERR.target"))
+	assert.Equal(t, "info", config.GetString("STDERR.logLevel"))
+	assert.Equal(t, "%15:04:05.000", config.GetString("STDERR-encoder.encodeTime"))
+	assert.Equal(t, "short", config.GetString("STDERR-encoder.encodeCaller"))
+	assert.Equal(t, "stacktrace", config.GetString("STDERR-encoder.stacktraceKey"))
+
+	assert.Equal(t, "error", config.GetString("FILE.logLevel"))
+	assert.Equal(t, "error", config.GetString("FILE.threshold"))
+	assert.Equal(t, "logger_name", config.GetString("FILE-encoder.nameKey"))
+	// the undefined variable is taken as an environment variable of cfzap.
+	assert.Equal(t, "${LOG_HOME:-../logs}/service.log", config.GetString("FILE-lumberjack.filename"))
+	assert.Equal(t, 100, config.GetInt("FILE-lumberjack.maxSize"))
+	assert.Equal(t, 14, config.GetInt("FILE-lumberjack.maxAge"))
+	assert.Contains(t, result.Warnings, "the total size cap of appender [FILE] is not supported, it's ignored")
+}
+
+func TestConvertErrors(t *testing.T) {
+	_, err := Convert([]byte("<Configuration>"))
+	assert.NotNil(t, err, "the XML is malformed.")
+
+	_, err = Convert([]byte("<log4net/>"))
+	assert.Equal(t, "unknown root element [log4net], it should be [Configuration] of log4j2 "+
+		"or [configuration] of logback", err.Error())
+
+	_, err = Convert([]byte(`<Configuration><Appenders><Console name="Console"/></Appenders></Configuration>`))
+	assert.Equal(t, "no appender referred by the root logger can be converted", err.Error())
+
+	result, err := Convert([]byte(`<Configuration><Appenders><Console name="Console"/></Appenders>
+		<Loggers><Root><AppenderRef ref="Console"/><AppenderRef ref="Missing"/></Root></Loggers></Configuration>`))
+	assert.Nil(t, err, "the defined appender should be converted.")
+	assert.Equal(t, []string{"appender [Missing] is referred but not defined"}, result.Warnings)
+
+	// the default level of root logger is 'error' in log4j2, and the default layout is '%m%n'.
+	config := readResult(t, result)
+	assert.Equal(t, "error", config.GetString("Console.logLevel"))
+	assert.Equal(t, "msg", config.GetString("Console-encoder.messageKey"))
+}
+
+func TestConvertLookups(t *testing.T) {
+	result, err := Convert([]byte(`<Configuration><Appenders>
+		<File name="Context" fileName="logs/${ctx:user}/app.log"><JsonLayout/></File>
+		<File name="Default" fileName="logs/${ctx:tenant:-shared}/app.log"><JsonLayout/></File>
+		</Appenders><Loggers><Root level="info"><AppenderRef ref="Context"/><AppenderRef ref="Default"/></Root></Loggers>
+		</Configuration>`))
+	assert.Nil(t, err, "fail to convert the lookups.")
+	assert.Contains(t, result.Warnings, "lookup [${ctx:user}] is not supported, it's written as literal text")
+	assert.Contains(t, result.Warnings, "lookup [${ctx:tenant:-shared}] is not supported, its default value is used")
+
+	// the converted config is loaded by cfzap, the unsupported lookup is kept as literal text.
+	description, err := cfzap.DescribeLogger(cfzap.NewConfigOption(cfzap.WithData(result.Config, "yaml")), "")
+	assert.Nil(t, err, "the converted config should be loaded.")
+	filenames := make(map[string]string)
+	for _, appender := range description.Appenders {
+		filenames[appender.Name] = appender.File.Filename
+	}
+	assert.Equal(t, map[string]string{"Context": "logs/${ctx:user}/app.log", "Default": "logs/shared/app.log"}, filenames)
+}
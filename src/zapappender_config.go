@@ -2,11 +2,14 @@ package cfzap
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -17,6 +20,9 @@ import (
 type appenderConfig struct {
 	// zapcore.writeSyncer used for creating logger.
 	writeSyncer *zapcore.WriteSyncer
+	// the writer behind writeSyncer which should be closed when the appender is no longer used.
+	// it's nil for 'stdout' and 'stderr'.
+	closer io.Closer
 	// zapcore.encoder used for creating logger.
 	encoder *zapcore.Encoder
 	// the zap.AtomicLevel used for creating logger.
@@ -25,206 +31,327 @@ type appenderConfig struct {
 	name string
 	// the zapcore.EncoderConfig needed by Encoder.
 	encoderConfig *zapcore.EncoderConfig
+	// the description which the appender is created from.
+	description *AppenderDescription
 }
 
 // loadAppenders loads all appenders defined in config file section 'appenders'.
 // it returns the successful loaded appender list , failed appender list and error object.
 func loadAppenders(config *viper.Viper) (map[string]*appenderConfig, map[string]error, error) {
 	// 'appenders' is the fixed top level key and cannot be ignored.
-	const SectionName = "appenders"
+	return loadAppenderList(config, "appenders")
+}
+
+// loadAppenderList loads all appenders listed in the given section, such as 'appenders' or 'loggers.audit.appenders'.
+// it returns the successful loaded appender list , failed appender list and error object.
+// the failed appender list maps the appender name to *AppenderError, and the error is *ConfigErrors
+// listing all of them when all appenders failed.
+func loadAppenderList(config *viper.Viper, sectionName string) (map[string]*appenderConfig, map[string]error, error) {
+	descriptions, errorAppenders, err := describeAppenderList(config, sectionName)
+	if err != nil {
+		return nil, errorAppenders, err
+	}
+
+	// create the writers and encoders of the described appenders.
+	// put it into error appender list if error happened.
+	appenders := make(map[string]*appenderConfig)
+	for appenderName, description := range descriptions {
+		if appender, err := newAppender(description); err == nil {
+			appenders[appenderName] = appender
+		} else {
+			errorAppenders[appenderName] = &AppenderError{Appender: appenderName, Key: errorKey(err), Err: err}
+		}
+	}
 
-	if !config.IsSet(SectionName) {
-		return nil, nil, fmt.Errorf("missing section [%s]", SectionName)
+	// there should be at least one successful loaded appender.
+	if len(appenders) == 0 {
+		return nil, errorAppenders, allAppendersFailed(errorAppenders)
 	}
 
-	appenderNames := config.Get(SectionName).([]interface{})
+	return appenders, errorAppenders, nil
+}
+
+// describeAppenderList resolves all appenders listed in the given section, no writer is opened
+// and no directory is created.
+// it returns the successful resolved appender list, failed appender list and error object as loadAppenderList().
+func describeAppenderList(config *viper.Viper, sectionName string) (map[string]*AppenderDescription, map[string]error, error) {
+	if !config.IsSet(sectionName) {
+		return nil, nil, &MissingSectionError{Key: sectionName}
+	}
+
+	appenderNames, ok := config.Get(sectionName).([]interface{})
+	if !ok {
+		return nil, nil, &InvalidValueError{Key: sectionName, Value: config.Get(sectionName),
+			Reason: "it should be a list of appender names"}
+	}
 
 	// at least one appender is required.
 	if len(appenderNames) == 0 {
-		return nil, nil, fmt.Errorf("no appender is defined in section [%s]", SectionName)
+		return nil, nil, &InvalidValueError{Key: sectionName, Value: appenderNames, Reason: "no appender is defined"}
 	}
 
-	appenders := make(map[string]*appenderConfig)
+	descriptions := make(map[string]*AppenderDescription)
 	errorAppenders := make(map[string]error)
 
 	// load appenders from config file and put them into a map to filter duplication.
-	for _, v := range appenderNames {
-		w := strings.TrimSpace(v.(string))
-		appenders[w] = nil
+	for i, v := range appenderNames {
+		name, err := appenderName(sectionName, i, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		descriptions[name] = nil
 	}
 
 	// load each appender info from corresponding section.
 	// the appender name is the section name.
 	// put it into error appender list if error happened.
-	for appenderName := range appenders {
-		if appender, err := loadAppender(config, appenderName); err == nil {
-			appenders[appenderName] = appender
+	for appenderName := range descriptions {
+		if description, err := describeAppender(config, appenderName); err == nil {
+			descriptions[appenderName] = description
 		} else {
-			errorAppenders[appenderName] = err
+			errorAppenders[appenderName] = &AppenderError{Appender: appenderName, Key: errorKey(err), Err: err}
 		}
 	}
 
 	// remove error appenders.
-	if len(errorAppenders) > 0 {
-		for k := range errorAppenders {
-			delete(appenders, k)
-		}
+	for k := range errorAppenders {
+		delete(descriptions, k)
 	}
 
 	// there should be at least one successful loaded appender.
-	if len(appenders) == 0 {
-		return nil, errorAppenders, fmt.Errorf("fail to load all %d appenders", len(errorAppenders))
+	if len(descriptions) == 0 {
+		return nil, errorAppenders, allAppendersFailed(errorAppenders)
 	}
 
-	return appenders, errorAppenders, nil
+	return descriptions, errorAppenders, nil
 }
 
-// loadAppender loads each appender according to its name.
-// it returns appenderConfig object and error object.
-func loadAppender(config *viper.Viper, appenderName string) (*appenderConfig, error) {
+// appenderName converts the i-th item of the appender list in the given section to the appender name.
+// It returns *InvalidValueError if the item is not a string or it's empty.
+func appenderName(sectionName string, i int, item interface{}) (string, error) {
+	key := fmt.Sprintf("%s[%d]", sectionName, i)
+
+	s, err := cast.ToStringE(item)
+	if err != nil {
+		return "", &InvalidValueError{Key: key, Value: item, Reason: "it should be an appender name"}
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		return "", &InvalidValueError{Key: key, Value: item, Reason: "it's empty"}
+	}
+
+	return s, nil
+}
+
+// allAppendersFailed returns *ConfigErrors listing the errors of all failed appenders.
+func allAppendersFailed(errorAppenders map[string]error) error {
+	errs := make([]error, 0, len(errorAppenders))
+	for _, err := range errorAppenders {
+		errs = append(errs, err)
+	}
+
+	return newConfigErrors(errs)
+}
+
+// describeAppender resolves the appender according to its name, no writer is opened and no directory is created.
+// it returns AppenderDescription object and error object.
+func describeAppender(config *viper.Viper, appenderName string) (*AppenderDescription, error) {
 	appenderSection := config.Sub(appenderName)
 	if appenderSection == nil {
-		return nil, fmt.Errorf("cannot find the entry for appender [%s]", appenderName)
+		return nil, &MissingSectionError{Key: appenderName}
 	}
 
-	appender := new(appenderConfig)
-	appender.name = appenderName
+	description := &AppenderDescription{Name: appenderName}
 
-	if err := loadAppenderWriteSyncer(config, appenderSection, appender); err != nil {
+	if err := describeAppenderTarget(config, appenderSection, description); err != nil {
 		return nil, err
 	}
-	if err := loadAppenderEncoderConfig(config, appenderSection, appender); err != nil {
+	if err := describeAppenderEncoderConfig(config, appenderSection, description); err != nil {
+		return nil, err
+	}
+
+	description.Level = describeAppenderLogLevel(appenderSection)
+	description.Threshold = describeAppenderThreshold(appenderSection)
+	description.EncoderType = describeAppenderEncoderType(appenderSection)
+
+	return description, nil
+}
+
+// newAppender creates the writer and encoder of the appender according to its description.
+// it returns appenderConfig object and error object.
+func newAppender(description *AppenderDescription) (*appenderConfig, error) {
+	appender := new(appenderConfig)
+	appender.name = description.Name
+	appender.description = description
+
+	if err := loadAppenderWriteSyncer(appender); err != nil {
 		return nil, err
 	}
 
-	loadAppenderLogLevel(appender, appenderSection)
-	// must be called after loadAppenderEncoderConfig() because Encoder needs EncoderConfig.
-	loadAppenderEncoder(appender, appenderSection)
+	appender.encoderConfig = newEncoderConfig(description.Encoder)
+	appender.logLevel = zap.NewAtomicLevelAt(description.Level)
+	// must be called after newEncoderConfig() because Encoder needs EncoderConfig.
+	loadAppenderEncoder(appender)
 
 	return appender, nil
 }
 
-// loadAppenderWriteSyncer loads WriteSyncer from corresponding appender section.
+// describeAppenderTarget resolves the target from corresponding appender section.
 // it returns error when the entry was missing.
-func loadAppenderWriteSyncer(config *viper.Viper, appenderSection *viper.Viper, appender *appenderConfig) error {
+func describeAppenderTarget(config *viper.Viper, appenderSection *viper.Viper, description *AppenderDescription) error {
 	// 'target' is the fixed and required key. the value should not be empty.
-	s, err := getRequiredString(appenderSection, appender.name, "target")
+	s, err := getRequiredString(appenderSection, description.Name, "target")
 	if err != nil {
 		return err
 	}
 
-	var syncer zapcore.WriteSyncer
+	description.Target = s
 
-	if strings.EqualFold(s, "stdout") {
-		syncer = zapcore.AddSync(os.Stdout)
-	} else if strings.EqualFold(s, "stderr") {
-		syncer = zapcore.AddSync(os.Stderr)
+	if strings.EqualFold(s, TargetStdout) {
+		description.TargetKind = TargetStdout
+	} else if strings.EqualFold(s, TargetStderr) {
+		description.TargetKind = TargetStderr
 	} else {
 		// find lumberjack section.
 		section := config.Sub(s)
 		if section == nil {
-			return fmt.Errorf("the value of [%s.target] is [%s], but the entry was missing", appender.name, s)
+			return &UnknownTargetError{Appender: description.Name, Target: s}
 		}
 
-		if writer, err := loadLumberjack(section); err != nil {
-			return err
-		} else {
-			syncer = zapcore.AddSync(writer)
-		}
+		description.TargetKind = TargetFile
+		description.File = describeLumberjack(section)
 	}
 
-	appender.writeSyncer = &syncer
-
 	return nil
 }
 
-// loadLumberjack loads lumberjack.Logger as io.Writer from config file.
-// it returns error when it failed to create log file path.
-func loadLumberjack(section *viper.Viper) (*lumberjack.Logger, error) {
-	writer := new(lumberjack.Logger)
+// describeLumberjack resolves the log file from lumberjack section.
+func describeLumberjack(section *viper.Viper) *FileDescription {
+	file := new(FileDescription)
 
 	// user must provide valid values.
-	writer.Compress = section.GetBool("compress")
-	writer.LocalTime = section.GetBool("localTime")
-	writer.MaxAge = section.GetInt("maxAge")
-	writer.MaxBackups = section.GetInt("maxBackups")
-	writer.MaxSize = section.GetInt("maxSize")
+	file.Compress = section.GetBool("compress")
+	file.LocalTime = section.GetBool("localTime")
+	file.MaxAge = section.GetInt("maxAge")
+	file.MaxBackups = section.GetInt("maxBackups")
+	file.MaxSize = section.GetInt("maxSize")
 
 	s := strings.TrimSpace(section.GetString("filename"))
 	// path.Dir() below only recognize '/' as separator.
 	s = strings.ReplaceAll(s, "\\", "/")
 
-	dir := path.Dir(s)
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return nil, err
+	file.Filename = path.Join(path.Dir(s), path.Base(s))
+
+	return file
+}
+
+// loadAppenderWriteSyncer creates WriteSyncer according to the target of appender.
+// it returns error when it failed to create log file path.
+func loadAppenderWriteSyncer(appender *appenderConfig) error {
+	var syncer zapcore.WriteSyncer
+
+	switch description := appender.description; description.TargetKind {
+	case TargetStdout:
+		syncer = zapcore.AddSync(os.Stdout)
+	case TargetStderr:
+		syncer = zapcore.AddSync(os.Stderr)
+	default:
+		if writer, err := loadLumberjack(description.File); err != nil {
+			return &InvalidValueError{Key: description.Target + ".filename", Value: description.File.Filename,
+				Reason: err.Error()}
+		} else {
+			file := &fileWriter{writer: writer}
+			syncer = zapcore.AddSync(file)
+			appender.closer = file
+		}
 	}
 
-	filename := path.Base(s)
-	writer.Filename = path.Join(dir, filename)
+	appender.writeSyncer = &syncer
 
-	return writer, nil
+	return nil
 }
 
-// loadAppenderEncoderConfig returns zapcore.EncoderConfig defined in config file.
+// loadLumberjack creates lumberjack.Logger as io.Writer according to the description of log file.
+// it returns error when it failed to create log file path.
+func loadLumberjack(file *FileDescription) (*lumberjack.Logger, error) {
+	if err := os.MkdirAll(path.Dir(file.Filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &lumberjack.Logger{
+		Filename:   file.Filename,
+		MaxSize:    file.MaxSize,
+		MaxAge:     file.MaxAge,
+		MaxBackups: file.MaxBackups,
+		LocalTime:  file.LocalTime,
+		Compress:   file.Compress,
+	}, nil
+}
+
+// describeAppenderEncoderConfig resolves the encoderConfig section defined in config file.
 // It returns error when encoderConfig entry was missing.
-func loadAppenderEncoderConfig(config *viper.Viper, appenderSection *viper.Viper, appender *appenderConfig) error {
+func describeAppenderEncoderConfig(config *viper.Viper, appenderSection *viper.Viper,
+	description *AppenderDescription) error {
 	// get value of appender encoderConfig section name first.
 	// 'encoderConfig' is the fixed and required name defined in appender section.
 	// its value can be any meaningful word, not required as 'encoderConfig'.
-	sectionName, err := getRequiredString(appenderSection, appender.name, "encoderConfig")
+	sectionName, err := getRequiredString(appenderSection, description.Name, "encoderConfig")
 	if err != nil {
 		return err
 	}
 
 	section := config.Sub(sectionName)
 	if section == nil {
-		return fmt.Errorf("the value of [%s.encoderConfig] is [%s], but the entry was missing",
-			appender.name, sectionName)
+		return &MissingSectionError{Key: sectionName, Referrer: description.Name + ".encoderConfig"}
 	}
 
-	encoderConfig := new(zapcore.EncoderConfig)
+	description.EncoderConfig = sectionName
+
+	// all keys are optional.
+	encoder := &description.Encoder
+	encoder.CallerKey = section.GetString("callerKey")
+	encoder.FunctionKey = section.GetString("functionKey")
+	encoder.LevelKey = section.GetString("levelKey")
+	encoder.MessageKey = section.GetString("messageKey")
+	encoder.NameKey = section.GetString("nameKey")
+	encoder.StacktraceKey = section.GetString("stacktraceKey")
+	encoder.TimeKey = section.GetString("timeKey")
+	encoder.ConsoleSeparator = section.GetString("consoleSeparator")
+	encoder.LineEnding = section.GetString("lineEnding")
+
+	encoder.EncodeDuration = strings.TrimSpace(section.GetString("encodeDuration"))
+	encoder.EncodeLevel = strings.TrimSpace(section.GetString("encodeLevel"))
+	encoder.EncodeName = strings.TrimSpace(section.GetString("encodeName"))
+	encoder.EncodeTime = strings.TrimSpace(section.GetString("encodeTime"))
+	encoder.EncodeCaller = strings.TrimSpace(section.GetString("encodeCaller"))
 
-	// deal with string properties. all keys are optional.
-	if section.IsSet("callerKey") {
-		encoderConfig.CallerKey = section.GetString("callerKey")
-	}
-	if section.IsSet("functionKey") {
-		encoderConfig.FunctionKey = section.GetString("functionKey")
-	}
-	if section.IsSet("levelKey") {
-		encoderConfig.LevelKey = section.GetString("levelKey")
-	}
-	if section.IsSet("messageKey") {
-		encoderConfig.MessageKey = section.GetString("messageKey")
-	}
-	if section.IsSet("nameKey") {
-		encoderConfig.NameKey = section.GetString("nameKey")
-	}
-	if section.IsSet("stacktraceKey") {
-		encoderConfig.StacktraceKey = section.GetString("stacktraceKey")
-	}
-	if section.IsSet("timeKey") {
-		encoderConfig.TimeKey = section.GetString("timeKey")
-	}
-	if section.IsSet("consoleSeparator") {
-		encoderConfig.ConsoleSeparator = section.GetString("consoleSeparator")
-	}
-	if section.IsSet("lineEnding") {
-		encoderConfig.LineEnding = section.GetString("lineEnding")
+	return nil
+}
+
+// newEncoderConfig returns zapcore.EncoderConfig according to its description.
+func newEncoderConfig(encoder EncoderDescription) *zapcore.EncoderConfig {
+	encoderConfig := &zapcore.EncoderConfig{
+		CallerKey:        encoder.CallerKey,
+		FunctionKey:      encoder.FunctionKey,
+		LevelKey:         encoder.LevelKey,
+		MessageKey:       encoder.MessageKey,
+		NameKey:          encoder.NameKey,
+		StacktraceKey:    encoder.StacktraceKey,
+		TimeKey:          encoder.TimeKey,
+		ConsoleSeparator: encoder.ConsoleSeparator,
+		LineEnding:       encoder.LineEnding,
 	}
 
 	// deal with typed properties. must use lower case.
-	if section.IsSet("encodeDuration") {
-		_ = encoderConfig.EncodeDuration.UnmarshalText(getLowerBytes(section, "encodeDuration"))
+	if encoder.EncodeDuration != "" {
+		_ = encoderConfig.EncodeDuration.UnmarshalText(toLowerBytes(encoder.EncodeDuration))
 	}
-	if section.IsSet("encodeLevel") {
-		_ = encoderConfig.EncodeLevel.UnmarshalText(getLowerBytes(section, "encodeLevel"))
+	if encoder.EncodeLevel != "" {
+		_ = encoderConfig.EncodeLevel.UnmarshalText(toLowerBytes(encoder.EncodeLevel))
 	}
-	if section.IsSet("encodeName") {
-		_ = encoderConfig.EncodeName.UnmarshalText(getLowerBytes(section, "encodeName"))
+	if encoder.EncodeName != "" {
+		_ = encoderConfig.EncodeName.UnmarshalText(toLowerBytes(encoder.EncodeName))
 	}
-	if section.IsSet("encodeTime") {
-		s := section.GetString("encodeTime")
+	if s := encoder.EncodeTime; s != "" {
 		if strings.Index(s, "%") == 0 { // customized format starts from '%'
 			s = s[1:]
 			// the format string is like "2006-01-02 15:04:05.999999999 -0700 MST",
@@ -233,56 +360,139 @@ func loadAppenderEncoderConfig(config *viper.Viper, appenderSection *viper.Viper
 				enc.AppendString(t.Format(s))
 			}
 		} else {
-			_ = encoderConfig.EncodeTime.UnmarshalText(getLowerBytes(section, "encodeTime"))
+			_ = encoderConfig.EncodeTime.UnmarshalText(toLowerBytes(s))
 		}
 	}
 
 	// no idea why it throws error when we don't set this filed.
-	_ = encoderConfig.EncodeCaller.UnmarshalText(getLowerBytes(section, "encodeCaller"))
+	_ = encoderConfig.EncodeCaller.UnmarshalText(toLowerBytes(encoder.EncodeCaller))
 
-	appender.encoderConfig = encoderConfig
-
-	return nil
+	return encoderConfig
 }
 
-// loadAppenderLogLevel loads log level defined in config file.
+// describeAppenderLogLevel resolves log level defined in config file.
 // Default value InfoLevel will be used when error occurs.
-func loadAppenderLogLevel(appender *appenderConfig, appenderSection *viper.Viper) {
-	atomicLevel := zap.NewAtomicLevel()
+func describeAppenderLogLevel(appenderSection *viper.Viper) zapcore.Level {
+	var level zapcore.Level
 
-	if atomicLevel.UnmarshalText(getLowerBytes(appenderSection, "logLevel")) != nil {
+	if level.UnmarshalText(getLowerBytes(appenderSection, "logLevel")) != nil {
 		// undefined value treated as InfoLevel.
-		atomicLevel.SetLevel(zap.InfoLevel)
+		level = zap.InfoLevel
+	}
+
+	return level
+}
+
+// describeAppenderThreshold resolves the threshold defined in config file.
+// Default value DebugLevel will be used when it's not set or error occurs, so all levels pass.
+func describeAppenderThreshold(appenderSection *viper.Viper) zapcore.Level {
+	var level zapcore.Level
+
+	// an empty string is taken as InfoLevel by zapcore.Level.
+	if !appenderSection.IsSet("threshold") || level.UnmarshalText(getLowerBytes(appenderSection, "threshold")) != nil {
+		level = zap.DebugLevel
 	}
 
-	appender.logLevel = atomicLevel
+	return level
 }
 
-// loadAppenderEncoder loads zapcore.Encoder defined in config file.
+// describeAppenderEncoderType resolves the encoder type defined in config file.
 // default value JSON encoder will be used when error occurs.
-func loadAppenderEncoder(appender *appenderConfig, appenderSection *viper.Viper) {
-	s := strings.ToLower(strings.TrimSpace(appenderSection.GetString("encoderType")))
+func describeAppenderEncoderType(appenderSection *viper.Viper) string {
+	if s := strings.ToLower(strings.TrimSpace(appenderSection.GetString("encoderType"))); s == "console" {
+		return "console"
+	}
+
+	// all other values are treated as JSON.
+	return "json"
+}
+
+// fileWriter wraps the lumberjack.Logger of an appender.
+// Close() waits for the writes in progress, and the writes after Close() are dropped,
+// because lumberjack.Logger opens the file again when it's written after closed.
+// note, lumberjack v2 never stops the goroutine removing the old log files, even after Close().
+type fileWriter struct {
+	writer *lumberjack.Logger
+	closed bool
+	lock   sync.RWMutex
+}
+
+// Write implements io.Writer.
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	return w.writer.Write(p)
+}
+
+// Close implements io.Closer, it's safe to call it more than once.
+func (w *fileWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.writer.Close()
+}
+
+// loadAppenderEncoder creates zapcore.Encoder according to the encoder type of appender.
+func loadAppenderEncoder(appender *appenderConfig) {
 	var encoder zapcore.Encoder
 
-	if s == "console" {
+	if appender.description.EncoderType == "console" {
 		encoder = zapcore.NewConsoleEncoder(*appender.encoderConfig)
 	} else {
-		// all other values are treated as JSON.
 		encoder = zapcore.NewJSONEncoder(*appender.encoderConfig)
 	}
 
 	appender.encoder = &encoder
 }
 
+// closeAppenders syncs and closes the writers of all the given appenders.
+// it returns the errors of the failed appenders, the map is empty when all appenders are closed successfully.
+func closeAppenders(appenders map[string]*appenderConfig) map[string]error {
+	errors := make(map[string]error)
+
+	for name, appender := range appenders {
+		err := (*appender.writeSyncer).Sync()
+
+		// 'stdout' and 'stderr' are never closed, and they cannot be synced when they are terminals or pipes,
+		// so the error is ignored.
+		if appender.closer == nil {
+			continue
+		}
+
+		if closeErr := appender.closer.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			errors[name] = err
+		}
+	}
+
+	return errors
+}
+
 // getLowerBytes returns a byte array from config.
 // It gets the string first, then trim it, finally covert it to byte array.
 func getLowerBytes(section *viper.Viper, key string) []byte {
-	s := strings.ToLower(strings.TrimSpace(section.GetString(key)))
-	return []byte(s)
+	return toLowerBytes(section.GetString(key))
+}
+
+// toLowerBytes trims the string and converts it to a lower case byte array.
+func toLowerBytes(s string) []byte {
+	return []byte(strings.ToLower(strings.TrimSpace(s)))
 }
 
 // getRequiredString returns a non empty string from config.
-// It returns error if the entry doesn't exist, or the value is empty.
+// It returns *MissingKeyError if the entry doesn't exist, or *InvalidValueError if the value is empty.
 func getRequiredString(section *viper.Viper, sectionName string, key string) (string, error) {
 	// InConfig() is case sensitive, must be lower case. IsSet() is not case sensitive.
 	if section.IsSet(key) {
@@ -291,8 +501,8 @@ func getRequiredString(section *viper.Viper, sectionName string, key string) (st
 			return value, nil
 		}
 
-		return "", fmt.Errorf("the value of [%s.%s] is empty", sectionName, key)
+		return "", &InvalidValueError{Key: sectionName + "." + key, Value: section.Get(key), Reason: "it's empty"}
 	}
 
-	return "", fmt.Errorf("the key [%s.%s] does not exist", sectionName, key)
+	return "", &MissingKeyError{Key: sectionName + "." + key}
 }
@@ -1,6 +1,7 @@
 package cfzap
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,7 +12,7 @@ func TestLoadAppendersOk(t *testing.T) {
 		WithFileName("appender_config_ok"),
 		WithFileExt("yaml"),
 		WithFilePaths(testFilePath))
-	config, err := readConfigFile(option)
+	config, _, err := readConfigFile(option)
 
 	assert.Nil(t, err, "fail to read appender config")
 
@@ -26,7 +27,7 @@ func TestLoadAppendersMissing(t *testing.T) {
 		WithFileName("appender_config_missing"),
 		WithFileExt("yaml"),
 		WithFilePaths(testFilePath))
-	config, err := readConfigFile(option)
+	config, _, err := readConfigFile(option)
 
 	assert.Nil(t, err, "fail to read appender config")
 
@@ -41,9 +42,44 @@ func TestAllAppendersFailed(t *testing.T) {
 		WithFileName("appender_config_fail_appenders"),
 		WithFileExt("yaml"),
 		WithFilePaths(testFilePath))
-	config, _ := readConfigFile(option)
+	config, _, _ := readConfigFile(option)
 	_, _, err := loadAppenders(config)
 
 	assert.NotNil(t, err, "there's no appender defined in section appenders.")
-	assert.Equal(t, "fail to load all 2 appenders", err.Error())
+	var configErrors *ConfigErrors
+	assert.True(t, errors.As(err, &configErrors), "all failed appenders should be listed.")
+	assert.Equal(t, 2, len(configErrors.Errors), "error count should be 2")
+
+	var missingKeyErr *MissingKeyError
+	assert.True(t, errors.As(err, &missingKeyErr), "the missing target should be reported.")
+	assert.Equal(t, "appender-stdout.target", missingKeyErr.Key)
+
+	var targetErr *UnknownTargetError
+	assert.True(t, errors.As(err, &targetErr), "the unknown target should be reported.")
+	assert.Equal(t, "appender-file", targetErr.Appender)
+	assert.Equal(t, "lumberjack", targetErr.Target)
+}
+
+func TestLoadAppendersInvalidList(t *testing.T) {
+	cases := map[string]string{
+		"appenders: [{name: appender-stdout}]": "appenders[0]",
+		"appenders:\n- appender-stdout\n- \n":  "appenders[1]",
+	}
+	for data, key := range cases {
+		config, _, err := readConfigFile(NewConfigOption(WithData([]byte(data), "yaml")))
+		assert.Nil(t, err, "fail to read appender config")
+
+		_, _, err = loadAppenders(config)
+		var invalid *InvalidValueError
+		assert.True(t, errors.As(err, &invalid), "the invalid item should be reported: %s", data)
+		assert.Equal(t, key, invalid.Key)
+	}
+
+	// a number is taken as the appender name.
+	config, _, err := readConfigFile(NewConfigOption(WithData([]byte("appenders: [1]"), "yaml")))
+	assert.Nil(t, err, "fail to read appender config")
+	_, _, err = loadAppenders(config)
+	var missing *MissingSectionError
+	assert.True(t, errors.As(err, &missing), "the appender section should be missing.")
+	assert.Equal(t, "1", missing.Key)
 }
@@ -0,0 +1,65 @@
+package cfzap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+)
+
+// loadNamedLevels loads the levels by logger names from the 'levels' section of the logger definition.
+// the top level 'levels' is used when loggerName is empty.
+// the names can be written as dotted keys or nested sections, both of below set the level of 'db.pool':
+//
+//	levels:
+//	  db.pool: debug
+//	  db:
+//	    pool: debug
+//
+// the invalid levels are ignored and reported by defaultLogger.
+// return empty map when there's no entry.
+func loadNamedLevels(config *viper.Viper, loggerName string) map[string]zapcore.Level {
+	levels := make(map[string]zapcore.Level)
+
+	// 'levels' is the fixed key in logger definition. its optional.
+	section := config.Get(loggerSectionName(loggerName, "levels"))
+	if values, ok := section.(map[string]interface{}); ok {
+		for name, err := range addNamedLevels(levels, "", values) {
+			defaultLogger.Warn("fail to load level of logger [" + name + "]: " + err.Error())
+		}
+	}
+
+	return levels
+}
+
+// addNamedLevels adds the levels in values into levels, the names in values are prefixed by prefix.
+// it returns the errors of the invalid levels.
+func addNamedLevels(levels map[string]zapcore.Level, prefix string, values map[string]interface{}) map[string]error {
+	errors := make(map[string]error)
+
+	for key, value := range values {
+		name := strings.TrimSpace(key)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, err := range addNamedLevels(levels, name, v) {
+				errors[k] = err
+			}
+		case string:
+			var level zapcore.Level
+			if err := level.UnmarshalText([]byte(strings.ToLower(strings.TrimSpace(v)))); err != nil {
+				errors[name] = err
+			} else {
+				levels[name] = level
+			}
+		default:
+			errors[name] = fmt.Errorf("unrecognized level: %v", value)
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,26 @@
+package cfzap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoadNamedLevels(t *testing.T) {
+	option := NewConfigOption(
+		WithFileName("level_config"),
+		WithFileExt("yaml"),
+		WithFilePaths(testFilePath))
+	config, _, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read level config")
+
+	levels := loadNamedLevels(config, "")
+	assert.Equal(t, map[string]zapcore.Level{
+		"http":    zapcore.WarnLevel,
+		"db.pool": zapcore.DebugLevel,
+		"db.conn": zapcore.ErrorLevel,
+	}, levels, "the invalid level should be ignored")
+
+	assert.Equal(t, 0, len(loadNamedLevels(config, "app")), "there's no levels for logger app")
+}
@@ -0,0 +1,139 @@
+package cfzap
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// 'loggers' is the fixed top level key for the loggers defined in one config file. its optional.
+// each entry in it is a logger name, which has its own 'appenders' and 'options' like the top level ones.
+// the appender, target and encoderConfig sections are shared by all loggers.
+const loggersSection = "loggers"
+
+// loggerSectionName returns the section name of the logger definition.
+// the top level is used when loggerName is empty.
+func loggerSectionName(loggerName string, key string) string {
+	if loggerName == "" {
+		return key
+	}
+
+	return loggersSection + "." + loggerName + "." + key
+}
+
+// definedLoggerNames returns the names of the loggers in the 'loggers' section, sorted by name.
+// a logger name may contain '.', such as 'audit.sql', it's found either as a key with '.' or as nested sections,
+// as GetNamedLogger() resolves it. a section is a logger if it has any key of logger, such as 'appenders',
+// otherwise the sections in it are searched.
+func definedLoggerNames(config *viper.Viper) []string {
+	loggers, ok := config.Get(loggersSection).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := addLoggerNames(nil, "", loggers)
+	sort.Strings(names)
+
+	// the same logger may be found both ways, when a key with '.' has been set by its dotted path.
+	unique := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			unique = append(unique, name)
+		}
+	}
+
+	return unique
+}
+
+// addLoggerNames adds the names of the loggers in section to names, prefix is the name of the section.
+func addLoggerNames(names []string, prefix string, section map[string]interface{}) []string {
+	for key, value := range section {
+		name := prefix + key
+
+		child, ok := value.(map[string]interface{})
+		if !ok || isLoggerSection(child) {
+			// the value which is not a section is reported as an invalid logger.
+			names = append(names, name)
+		}
+		if ok {
+			names = addLoggerNames(names, name+".", childSections(child))
+		}
+	}
+
+	return names
+}
+
+// isLoggerSection checks to see if the section has any key of logger, such as 'appenders'.
+func isLoggerSection(section map[string]interface{}) bool {
+	for key := range section {
+		if containsFold(loggerKeys, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// childSections returns the values of section which may be loggers, the keys of logger are excluded.
+func childSections(section map[string]interface{}) map[string]interface{} {
+	children := make(map[string]interface{})
+	for key, value := range section {
+		if _, ok := value.(map[string]interface{}); ok && !containsFold(loggerKeys, key) {
+			children[key] = value
+		}
+	}
+
+	return children
+}
+
+// loadLoggerAppenders loads the appenders of the logger defined in 'loggers' section.
+// the top level 'appenders' is used when loggerName is empty.
+// it returns the successful loaded appender list , failed appender list and error object.
+func loadLoggerAppenders(config *viper.Viper, loggerName string) (map[string]*appenderConfig, map[string]error, error) {
+	if err := checkLoggerDefined(config, loggerName); err != nil {
+		return nil, nil, err
+	}
+
+	return loadAppenderList(config, loggerSectionName(loggerName, "appenders"))
+}
+
+// describeLoggerAppenders resolves the appenders of the logger as loadLoggerAppenders(),
+// but no writer is opened and no directory is created.
+func describeLoggerAppenders(config *viper.Viper, loggerName string) (map[string]*AppenderDescription,
+	map[string]error, error) {
+	if err := checkLoggerDefined(config, loggerName); err != nil {
+		return nil, nil, err
+	}
+
+	return describeAppenderList(config, loggerSectionName(loggerName, "appenders"))
+}
+
+// checkLoggerDefined returns error if the logger is not defined in 'loggers' section.
+// the top level logger is always defined.
+func checkLoggerDefined(config *viper.Viper, loggerName string) error {
+	if loggerName != "" && config.Sub(loggersSection+"."+loggerName) == nil {
+		return fmt.Errorf("logger [%s] is not defined in section [%s]", loggerName, loggersSection)
+	}
+
+	return nil
+}
+
+// loadLoggerOptions loads the options of the logger defined in 'loggers' section.
+// the top level 'options' is used when loggerName is empty.
+// return empty option list when there's no entry.
+func loadLoggerOptions(config *viper.Viper, loggerName string) []zap.Option {
+	if loggerName == "" {
+		return loadLogOptions(config)
+	}
+
+	return loadOptionSection(config.Sub(loggerSectionName(loggerName, "options")))
+}
+
+// describeLoggerOptions resolves the options of the logger defined in 'loggers' section.
+// the top level 'options' is used when loggerName is empty.
+func describeLoggerOptions(config *viper.Viper, loggerName string) OptionsDescription {
+	// 'options' is the fixed key in logger definition. its optional.
+	return describeOptionSection(config.Sub(loggerSectionName(loggerName, "options")))
+}
@@ -0,0 +1,49 @@
+package cfzap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadLoggerAppenders(t *testing.T) {
+	option := NewConfigOption(
+		WithFileName("logger_config"),
+		WithFileExt("yaml"),
+		WithFilePaths(testFilePath))
+	config, _, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read logger config")
+
+	appenders, _, err := loadLoggerAppenders(config, "")
+	assert.Nil(t, err, "fail to load top level appenders")
+	assert.Equal(t, 1, len(appenders), "top level appender count should be 1")
+
+	appenders, _, err = loadLoggerAppenders(config, "app")
+	assert.Nil(t, err, "fail to load appenders of logger app")
+	assert.Equal(t, 2, len(appenders), "appender count of logger app should be 2")
+
+	appenders, _, err = loadLoggerAppenders(config, "audit")
+	assert.Nil(t, err, "fail to load appenders of logger audit")
+	assert.NotNil(t, appenders["appender-stderr"], "logger audit should use appender-stderr")
+
+	_, errors, err := loadLoggerAppenders(config, "broken")
+	assert.NotNil(t, err, "all appenders of logger broken should be failed")
+	assert.Equal(t, 1, len(errors), "error count of logger broken should be 1")
+
+	_, _, err = loadLoggerAppenders(config, "unknown")
+	assert.NotNil(t, err, "logger unknown is not defined")
+	assert.Equal(t, "logger [unknown] is not defined in section [loggers]", err.Error())
+}
+
+func TestLoadLoggerOptions(t *testing.T) {
+	option := NewConfigOption(
+		WithFileName("logger_config"),
+		WithFileExt("yaml"),
+		WithFilePaths(testFilePath))
+	config, _, err := readConfigFile(option)
+	assert.Nil(t, err, "fail to read logger config")
+
+	assert.Equal(t, 0, len(loadLoggerOptions(config, "")), "there's no top level options")
+	assert.Equal(t, 1, len(loadLoggerOptions(config, "app")), "there should be 1 option for logger app")
+	assert.Equal(t, 0, len(loadLoggerOptions(config, "audit")), "there's no options for logger audit")
+}
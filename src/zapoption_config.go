@@ -1,6 +1,8 @@
 package cfzap
 
 import (
+	"sort"
+
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
@@ -9,23 +11,28 @@ import (
 // return empty option list when there's no entry.
 func loadLogOptions(config *viper.Viper) []zap.Option {
 	// 'options' is the fixed top level key. its optional.
-	section := config.Sub("options")
-	var options []zap.Option
+	return loadOptionSection(config.Sub("options"))
+}
+
+// loadOptionSection loads options from the given section, section can be nil.
+// return empty option list when there's no entry.
+func loadOptionSection(section *viper.Viper) []zap.Option {
+	return newOptions(describeOptionSection(section))
+}
+
+// describeOptionSection resolves options from the given section, section can be nil.
+func describeOptionSection(section *viper.Viper) OptionsDescription {
+	var description OptionsDescription
 
 	if section == nil {
-		// return empty option list when there is no 'options' section.
-		return options
+		// return empty options when there is no 'options' section.
+		return description
 	}
 
 	// 'caller' is the fixed key inside options. its optional.
-	if section.GetBool("caller") {
-		options = append(options, zap.AddCaller())
-	}
-
+	description.Caller = section.GetBool("caller")
 	// 'development' is the fixed key inside options. its optional.
-	if section.GetBool("development") {
-		options = append(options, zap.Development())
-	}
+	description.Development = section.GetBool("development")
 
 	// 'fields' is the fixed key inside options. its optional.
 	section = section.Sub("fields")
@@ -33,13 +40,41 @@ func loadLogOptions(config *viper.Viper) []zap.Option {
 		keys := section.AllKeys()
 
 		if count := len(keys); count > 0 {
-			fields := make([]zap.Field, count)
-			for i, key := range keys {
-				fields[i] = zap.String(key, section.GetString(key))
+			description.Fields = make(map[string]string, count)
+			for _, key := range keys {
+				description.Fields[key] = section.GetString(key)
 			}
+		}
+	}
 
-			options = append(options, zap.Fields(fields...))
+	return description
+}
+
+// newOptions returns the options according to their description.
+func newOptions(description OptionsDescription) []zap.Option {
+	var options []zap.Option
+
+	if description.Caller {
+		options = append(options, zap.AddCaller())
+	}
+
+	if description.Development {
+		options = append(options, zap.Development())
+	}
+
+	if count := len(description.Fields); count > 0 {
+		keys := make([]string, 0, count)
+		for key := range description.Fields {
+			keys = append(keys, key)
 		}
+		sort.Strings(keys)
+
+		fields := make([]zap.Field, count)
+		for i, key := range keys {
+			fields[i] = zap.String(key, description.Fields[key])
+		}
+
+		options = append(options, zap.Fields(fields...))
 	}
 
 	return options
@@ -20,7 +20,7 @@ func testCase(t *testing.T, targetCount int) {
 		WithFileExt("yaml"),
 		WithFilePaths(testFilePath))
 
-	config, err := readConfigFile(option)
+	config, _, err := readConfigFile(option)
 	assert.Nilf(t, err, "fail to read config file for target %d", targetCount)
 
 	options := loadLogOptions(config)